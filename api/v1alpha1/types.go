@@ -0,0 +1,87 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReloadStatusName is the name of the (single) ReloadStatus object maintained by the controller per namespace.
+const ReloadStatusName = "pod-reloader"
+
+// Condition types used in WorkloadStatus.
+const (
+	// The pods of the workload use the current configuration.
+	ConditionTypeUpToDate = "UpToDate"
+	// A reload of the workload was deferred by the controller (e.g. due to maintenance windows or rate limits); the reason tells why.
+	ConditionTypePending = "Pending"
+	// Some of the objects referenced by the workload do not exist.
+	ConditionTypeReferenceMissing = "ReferenceMissing"
+)
+
+// ReloadStatusStatus defines the observed state of ReloadStatus.
+type ReloadStatusStatus struct {
+	// Workloads of the namespace which are tracked by pod-reloader, that is, which reference config maps or secrets through annotations.
+	// +optional
+	// +listType=map
+	// +listMapKey=kind
+	// +listMapKey=name
+	Workloads []WorkloadStatus `json:"workloads,omitempty"`
+}
+
+// WorkloadStatus describes the reload state of a single tracked workload.
+type WorkloadStatus struct {
+	// Kind of the workload (Deployment, StatefulSet, DaemonSet).
+	Kind string `json:"kind"`
+	// Name of the workload.
+	Name string `json:"name"`
+	// Referenced config maps.
+	// +optional
+	ConfigMaps []string `json:"configMaps,omitempty"`
+	// Referenced secrets.
+	// +optional
+	Secrets []string `json:"secrets,omitempty"`
+	// Reload strategy of the workload.
+	Strategy string `json:"strategy"`
+	// Configuration hash the pods of the workload are currently running with.
+	// +optional
+	CurrentHash string `json:"currentHash,omitempty"`
+	// Configuration hash calculated from the current state of the referenced config maps and secrets.
+	DesiredHash string `json:"desiredHash"`
+	// Time when the current configuration hash was first observed as applied.
+	// +optional
+	LastReloadTime *metav1.Time `json:"lastReloadTime,omitempty"`
+	// Conditions of the workload.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ReloadStatus reports the reload state of all workloads tracked by pod-reloader in a namespace;
+// it is maintained by the controller, and there is at most one instance per namespace, named pod-reloader.
+type ReloadStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ReloadStatusStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReloadStatusList contains a list of ReloadStatus.
+type ReloadStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReloadStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReloadStatus{}, &ReloadStatusList{})
+}
@@ -0,0 +1,332 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// kubectl-pod_reloader is a kubectl plugin to inspect and trigger reloads performed by pod-reloader.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const usage = `Inspect and trigger reloads performed by pod-reloader.
+
+Usage:
+  kubectl pod-reloader [flags] deps (configmap|secret) <name>   List workloads depending on the given config map or secret
+  kubectl pod-reloader [flags] status <kind>/<name>             Show the reload status of the given workload
+  kubectl pod-reloader [flags] hash <kind>/<name>               Print the current configuration hash of the given workload
+  kubectl pod-reloader [flags] trigger <kind>/<name>            Trigger a reload of the given workload
+  kubectl pod-reloader [flags] render [<file>...]               Set configuration hashes in the given manifests (offline)
+
+Supported workload kinds are deployment (deploy), statefulset (sts) and daemonset (ds).
+
+The render command reads manifests from the given files (or stdin, if no file or '-' is given), and writes
+them to stdout, with the configuration hash of annotated workloads calculated from the config maps and secrets
+contained in the manifests; this requires the workloads to be annotated with pod-reloader.cs.sap.com/hash-mode: content.
+
+Flags:
+`
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+}
+
+func main() {
+	var kubeconfig string
+	var kubecontext string
+	var namespace string
+	flags := flag.NewFlagSet("kubectl-pod_reloader", flag.ExitOnError)
+	flags.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use.")
+	flags.StringVar(&kubecontext, "context", "", "The name of the kubeconfig context to use.")
+	flags.StringVar(&namespace, "namespace", "", "The namespace to use; defaults to the namespace of the current context.")
+	flags.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	args := flags.Args()
+
+	if args[0] == "render" {
+		// render works offline, without cluster access
+		if namespace == "" {
+			namespace = "default"
+		}
+		if err := render(ctx, namespace, args[1:], os.Stdin, os.Stdout); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig, Precedence: clientcmd.NewDefaultClientConfigLoadingRules().Precedence},
+		&clientcmd.ConfigOverrides{CurrentContext: kubecontext, Context: clientcmdapi.Context{Namespace: namespace}},
+	)
+	if namespace == "" {
+		var err error
+		if namespace, _, err = clientConfig.Namespace(); err != nil {
+			fail(err)
+		}
+	}
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		fail(err)
+	}
+	client, err := ctrlclient.New(config, ctrlclient.Options{Scheme: scheme})
+	if err != nil {
+		fail(err)
+	}
+
+	switch args[0] {
+	case "deps":
+		if len(args) != 3 {
+			fail(fmt.Errorf("usage: deps (configmap|secret) <name>"))
+		}
+		err = deps(ctx, client, namespace, args[1], args[2], os.Stdout)
+	case "status":
+		if len(args) != 2 {
+			fail(fmt.Errorf("usage: status <kind>/<name>"))
+		}
+		err = status(ctx, client, namespace, args[1], os.Stdout)
+	case "hash":
+		if len(args) != 2 {
+			fail(fmt.Errorf("usage: hash <kind>/<name>"))
+		}
+		err = hash(ctx, client, namespace, args[1], os.Stdout)
+	case "trigger":
+		if len(args) != 2 {
+			fail(fmt.Errorf("usage: trigger <kind>/<name>"))
+		}
+		err = trigger(ctx, client, namespace, args[1], os.Stdout)
+	default:
+		err = fmt.Errorf("unknown command: %s", args[0])
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+// list the workloads which are annotated with a reference to the given config map or secret
+func deps(ctx context.Context, client ctrlclient.Client, namespace string, kind string, name string, out io.Writer) error {
+	var annotation string
+	switch strings.ToLower(kind) {
+	case "configmap", "configmaps", "cm":
+		annotation = reloader.AnnotationConfigMaps
+		kind = reloader.KindConfigMap
+	case "secret", "secrets":
+		annotation = reloader.AnnotationSecrets
+		kind = reloader.KindSecret
+	default:
+		return fmt.Errorf("invalid kind (must be configmap or secret): %s", kind)
+	}
+
+	objects, err := listWorkloads(ctx, client, namespace)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tSTRATEGY\tUP-TO-DATE")
+	for _, object := range objects {
+		if !slices.Contains(reloader.SplitNames(object.GetAnnotations()[annotation]), name) {
+			continue
+		}
+		strategy := workloadStrategy(object)
+		hash, err := reloader.GenerateHashForObject(ctx, client, object)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", workloadKind(object), object.GetName(), strategy, reloader.AppliedHash(object, strategy) == hash)
+	}
+	return w.Flush()
+}
+
+// print the references and hashes of the given workload
+func status(ctx context.Context, client ctrlclient.Client, namespace string, workload string, out io.Writer) error {
+	object, err := getWorkload(ctx, client, namespace, workload)
+	if err != nil {
+		return err
+	}
+	annotations := object.GetAnnotations()
+	strategy := workloadStrategy(object)
+	hash, err := reloader.GenerateHashForObject(ctx, client, object)
+	if err != nil {
+		return err
+	}
+	appliedHash := reloader.AppliedHash(object, strategy)
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Workload:\t%s/%s\n", workloadKind(object), object.GetName())
+	fmt.Fprintf(w, "Namespace:\t%s\n", object.GetNamespace())
+	for _, name := range reloader.SplitNames(annotations[reloader.AnnotationConfigMaps]) {
+		fmt.Fprintf(w, "ConfigMap:\t%s%s\n", name, missingSuffix(ctx, client, namespace, name, &corev1.ConfigMap{}))
+	}
+	for _, name := range reloader.SplitNames(annotations[reloader.AnnotationSecrets]) {
+		fmt.Fprintf(w, "Secret:\t%s%s\n", name, missingSuffix(ctx, client, namespace, name, &corev1.Secret{}))
+	}
+	fmt.Fprintf(w, "Strategy:\t%s\n", strategy)
+	if s := annotations[reloader.AnnotationMaintenanceWindows]; s != "" {
+		fmt.Fprintf(w, "Maintenance windows:\t%s\n", s)
+	}
+	fmt.Fprintf(w, "Current hash:\t%s\n", appliedHash)
+	fmt.Fprintf(w, "Desired hash:\t%s\n", hash)
+	fmt.Fprintf(w, "Up-to-date:\t%t\n", appliedHash == hash)
+	return w.Flush()
+}
+
+// print the configuration hash of the given workload, as calculated from the current state of the referenced objects
+func hash(ctx context.Context, client ctrlclient.Client, namespace string, workload string, out io.Writer) error {
+	object, err := getWorkload(ctx, client, namespace, workload)
+	if err != nil {
+		return err
+	}
+	hash, err := reloader.GenerateHashForObject(ctx, client, object)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, hash)
+	return err
+}
+
+// trigger a reload of the given workload, the same way the controller does (by injecting the current hash);
+// nothing is done if the workload already uses the current configuration
+func trigger(ctx context.Context, client ctrlclient.Client, namespace string, workload string, out io.Writer) error {
+	object, err := getWorkload(ctx, client, namespace, workload)
+	if err != nil {
+		return err
+	}
+	annotations := object.GetAnnotations()
+	if !reloader.HasReferences(annotations) {
+		return fmt.Errorf("workload %s is not annotated with references to config maps, secrets or other resources", workload)
+	}
+	hash, err := reloader.GenerateHashForObject(ctx, client, object)
+	if err != nil {
+		return err
+	}
+	if reloader.AppliedHash(object, workloadStrategy(object)) == hash {
+		_, err = fmt.Fprintf(out, "%s/%s already uses the current configuration; nothing triggered\n", workloadKind(object), object.GetName())
+		return err
+	}
+	reloader.InjectHash(object, hash)
+	if err := client.Update(ctx, object); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s/%s triggered\n", workloadKind(object), object.GetName())
+	return err
+}
+
+func getWorkload(ctx context.Context, client ctrlclient.Client, namespace string, workload string) (ctrlclient.Object, error) {
+	kind, name, ok := strings.Cut(workload, "/")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("invalid workload (must be of the form <kind>/<name>): %s", workload)
+	}
+	var object ctrlclient.Object
+	// add additional workload types here
+	switch strings.ToLower(kind) {
+	case "deployment", "deployments", "deploy":
+		object = &appsv1.Deployment{}
+	case "statefulset", "statefulsets", "sts":
+		object = &appsv1.StatefulSet{}
+	case "daemonset", "daemonsets", "ds":
+		object = &appsv1.DaemonSet{}
+	default:
+		return nil, fmt.Errorf("unsupported workload kind: %s", kind)
+	}
+	if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: name}, object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func listWorkloads(ctx context.Context, client ctrlclient.Client, namespace string) ([]ctrlclient.Object, error) {
+	var objects []ctrlclient.Object
+
+	// add additional workload types here
+	deploymentList := &appsv1.DeploymentList{}
+	if err := client.List(ctx, deploymentList, ctrlclient.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range deploymentList.Items {
+		objects = append(objects, &deploymentList.Items[i])
+	}
+	statefulSetList := &appsv1.StatefulSetList{}
+	if err := client.List(ctx, statefulSetList, ctrlclient.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range statefulSetList.Items {
+		objects = append(objects, &statefulSetList.Items[i])
+	}
+	daemonSetList := &appsv1.DaemonSetList{}
+	if err := client.List(ctx, daemonSetList, ctrlclient.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range daemonSetList.Items {
+		objects = append(objects, &daemonSetList.Items[i])
+	}
+
+	return objects, nil
+}
+
+func workloadKind(object ctrlclient.Object) string {
+	switch object.(type) {
+	// add additional workload types here
+	case *appsv1.Deployment:
+		return "Deployment"
+	case *appsv1.StatefulSet:
+		return "StatefulSet"
+	case *appsv1.DaemonSet:
+		return "DaemonSet"
+	default:
+		return fmt.Sprintf("%T", object)
+	}
+}
+
+func workloadStrategy(object ctrlclient.Object) string {
+	if strategy := object.GetAnnotations()[reloader.AnnotationStrategy]; strategy != "" {
+		return strategy
+	}
+	return reloader.StrategyRestart
+}
+
+func missingSuffix(ctx context.Context, client ctrlclient.Client, namespace string, name string, object ctrlclient.Object) string {
+	if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: name}, object); apierrors.IsNotFound(err) {
+		return " (missing)"
+	} else if err != nil {
+		return fmt.Sprintf(" (error: %s)", err)
+	}
+	return ""
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "error: %s\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,84 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test plugin commands", func() {
+	var cli ctrlclient.Client
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		cli = fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"}, Data: map[string]string{"key": "value"}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app", Annotations: map[string]string{reloader.AnnotationConfigMaps: "config,missing"}}},
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "db", Annotations: map[string]string{reloader.AnnotationSecrets: "credentials"}}},
+			&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "agent"}},
+		).Build()
+		out = &bytes.Buffer{}
+	})
+
+	It("should list dependent workloads", func() {
+		Expect(deps(ctx, cli, "test", "cm", "config", out)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("Deployment"))
+		Expect(out.String()).NotTo(ContainSubstring("StatefulSet"))
+
+		out.Reset()
+		Expect(deps(ctx, cli, "test", "secret", "credentials", out)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("StatefulSet"))
+		Expect(out.String()).NotTo(ContainSubstring("Deployment"))
+
+		Expect(deps(ctx, cli, "test", "pod", "app", out)).To(MatchError(ContainSubstring("invalid kind")))
+	})
+
+	It("should show the status and hash of a workload", func() {
+		Expect(status(ctx, cli, "test", "deploy/app", out)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("missing (missing)"))
+		Expect(out.String()).To(MatchRegexp(`Up-to-date:\s+false`))
+
+		out.Reset()
+		Expect(hash(ctx, cli, "test", "deployment/app", out)).To(Succeed())
+		Expect(out.String()).To(MatchRegexp(`^[0-9a-f]{64}\n$`))
+
+		Expect(hash(ctx, cli, "test", "app", out)).To(MatchError(ContainSubstring("invalid workload")))
+		Expect(hash(ctx, cli, "test", "job/app", out)).To(MatchError(ContainSubstring("unsupported workload kind")))
+	})
+
+	It("should trigger a reload only if the configuration is outdated", func() {
+		Expect(trigger(ctx, cli, "test", "ds/agent", out)).To(MatchError(ContainSubstring("not annotated")))
+
+		Expect(trigger(ctx, cli, "test", "deploy/app", out)).To(Succeed())
+		Expect(out.String()).To(Equal("Deployment/app triggered\n"))
+		deployment := &appsv1.Deployment{}
+		Expect(cli.Get(ctx, ctrlclient.ObjectKey{Namespace: "test", Name: "app"}, deployment)).To(Succeed())
+		injectedHash := deployment.Annotations[reloader.AnnotationConfigHash]
+		Expect(injectedHash).NotTo(BeEmpty())
+
+		// simulate the webhook
+		delete(deployment.Annotations, reloader.AnnotationConfigHash)
+		deployment.Spec.Template.Annotations = map[string]string{reloader.AnnotationConfigHash: injectedHash}
+		Expect(cli.Update(ctx, deployment)).To(Succeed())
+
+		out.Reset()
+		Expect(trigger(ctx, cli, "test", "deploy/app", out)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("nothing triggered"))
+		Expect(cli.Get(ctx, ctrlclient.ObjectKey{Namespace: "test", Name: "app"}, deployment)).To(Succeed())
+		Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationConfigHash))
+	})
+})
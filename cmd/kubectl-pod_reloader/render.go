@@ -0,0 +1,198 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+// render reads manifests from the given files (or stdin), calculates the configuration hash of all annotated workloads
+// from the config maps and secrets contained in the manifests, and writes the manifests, with the hash set, to stdout;
+// since uid and resource version are not known offline, workloads must use the content hash mode
+func render(ctx context.Context, namespace string, files []string, in io.Reader, out io.Writer) error {
+	var objects []*unstructured.Unstructured
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, file := range files {
+		fileObjects, err := readManifests(file, in)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, fileObjects...)
+	}
+
+	client := newObjectReader()
+	for _, object := range objects {
+		switch object.GroupVersionKind() {
+		case corev1.SchemeGroupVersion.WithKind("ConfigMap"):
+			configMap := &corev1.ConfigMap{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, configMap); err != nil {
+				return err
+			}
+			if configMap.Namespace == "" {
+				configMap.Namespace = namespace
+			}
+			client.add(configMap)
+		case corev1.SchemeGroupVersion.WithKind("Secret"):
+			secret := &corev1.Secret{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, secret); err != nil {
+				return err
+			}
+			// the api server merges stringData into data; do the same here, so the hash matches the hash calculated online
+			for key, value := range secret.StringData {
+				if secret.Data == nil {
+					secret.Data = make(map[string][]byte)
+				}
+				secret.Data[key] = []byte(value)
+			}
+			secret.StringData = nil
+			if secret.Type == "" {
+				secret.Type = corev1.SecretTypeOpaque
+			}
+			if secret.Namespace == "" {
+				secret.Namespace = namespace
+			}
+			client.add(secret)
+		}
+	}
+
+	var buf bytes.Buffer
+	for i, object := range objects {
+		annotations := object.GetAnnotations()
+		if reloader.HasReferences(annotations) {
+			var path []string
+			// add additional workload types here
+			switch object.GroupVersionKind().GroupKind().String() {
+			case "Deployment.apps", "StatefulSet.apps", "DaemonSet.apps":
+				path = []string{"spec", "template", "metadata", "annotations"}
+			case "Pod":
+				path = []string{"metadata", "annotations"}
+			}
+			if path != nil {
+				if annotations[reloader.AnnotationHashMode] != reloader.HashModeContent {
+					return fmt.Errorf("%s %s/%s: hash can only be calculated offline with annotation %s: %s", object.GetKind(), object.GetNamespace(), object.GetName(), reloader.AnnotationHashMode, reloader.HashModeContent)
+				}
+				// note: image digest revisions are maintained by the controller on the live workload, so the rendered hash would be outdated after the first digest change
+				if annotations[reloader.AnnotationSecretProviderClasses] != "" || annotations[reloader.AnnotationResources] != "" || reloader.IncludesImplicitSecrets(annotations) || reloader.TracksImageDigests(annotations) {
+					return fmt.Errorf("%s %s/%s: hash cannot be calculated offline for workloads referencing secret provider classes or other resources, including implicit secrets, or tracking image digests", object.GetKind(), object.GetNamespace(), object.GetName())
+				}
+				// note: the namespace is only defaulted for the hash calculation, the manifests are written without it
+				objectWithNamespace := object.DeepCopy()
+				if objectWithNamespace.GetNamespace() == "" {
+					objectWithNamespace.SetNamespace(namespace)
+				}
+				hash, err := reloader.GenerateHashForObject(ctx, client, objectWithNamespace)
+				if err != nil {
+					return err
+				}
+				if err := unstructured.SetNestedField(object.Object, hash, append(path, reloader.AnnotationConfigHash)...); err != nil {
+					return err
+				}
+			}
+		}
+		raw, err := yaml.Marshal(object.Object)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(raw)
+	}
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+// read the manifests contained in the given file ("-" meaning the given reader)
+func readManifests(file string, in io.Reader) ([]*unstructured.Unstructured, error) {
+	r := in
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		object := &unstructured.Unstructured{}
+		if err := decoder.Decode(&object.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error decoding %s: %w", file, err)
+		}
+		if len(object.Object) == 0 {
+			continue
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// objectReader is a minimal in-memory reader over the config maps and secrets contained in the rendered manifests;
+// it supports reading single objects only, which is all the hash calculation needs for config maps and secrets
+type objectReader struct {
+	objects map[schema.GroupVersionKind]map[ctrlclient.ObjectKey]ctrlclient.Object
+}
+
+var _ ctrlclient.Reader = &objectReader{}
+
+func newObjectReader() *objectReader {
+	return &objectReader{objects: make(map[schema.GroupVersionKind]map[ctrlclient.ObjectKey]ctrlclient.Object)}
+}
+
+func (r *objectReader) add(object ctrlclient.Object) {
+	gvk, err := apiutil.GVKForObject(object, scheme)
+	if err != nil {
+		panic(err)
+	}
+	if r.objects[gvk] == nil {
+		r.objects[gvk] = make(map[ctrlclient.ObjectKey]ctrlclient.Object)
+	}
+	r.objects[gvk][ctrlclient.ObjectKeyFromObject(object)] = object
+}
+
+func (r *objectReader) Get(ctx context.Context, key ctrlclient.ObjectKey, object ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+	gvk, err := apiutil.GVKForObject(object, scheme)
+	if err != nil {
+		return err
+	}
+	existing, ok := r.objects[gvk][key]
+	if !ok {
+		return apierrors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind) + "s"}, key.Name)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, object)
+}
+
+func (r *objectReader) List(ctx context.Context, list ctrlclient.ObjectList, opts ...ctrlclient.ListOption) error {
+	return fmt.Errorf("listing objects is not supported offline")
+}
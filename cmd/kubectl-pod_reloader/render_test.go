@@ -0,0 +1,110 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const renderManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+stringData:
+  password: secret
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    pod-reloader.cs.sap.com/configmaps: config,missing
+    pod-reloader.cs.sap.com/secrets: credentials
+    pod-reloader.cs.sap.com/hash-mode: content
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - name: app
+        image: app
+`
+
+var _ = Describe("Test offline rendering", func() {
+	It("should set the hash calculated online", func() {
+		var out bytes.Buffer
+		Expect(render(ctx, "test", nil, strings.NewReader(renderManifests), &out)).To(Succeed())
+		objects, err := readManifests("-", &out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(HaveLen(3))
+		Expect(objects[2].GetNamespace()).To(BeEmpty())
+		hash, found, err := unstructured.NestedString(objects[2].Object, "spec", "template", "metadata", "annotations", reloader.AnnotationConfigHash)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+
+		// online, uid and resource version differ, and the api server has merged stringData into data
+		cli := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config", UID: "uid1"}, Data: map[string]string{"key": "value"}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "credentials", UID: "uid2"}, Type: corev1.SecretTypeOpaque, Data: map[string][]byte{"password": []byte("secret")}},
+		).Build()
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app", Annotations: objects[2].GetAnnotations()}}
+		Expect(reloader.GenerateHashForObject(ctx, cli, deployment)).To(Equal(hash))
+	})
+
+	It("should pass through objects without references, and read manifests from files", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "manifests.yaml")
+		Expect(os.WriteFile(file, []byte(strings.ReplaceAll(renderManifests, "pod-reloader.cs.sap.com/", "example.com/")), 0o644)).To(Succeed())
+		var out bytes.Buffer
+		Expect(render(ctx, "test", []string{file}, nil, &out)).To(Succeed())
+		objects, err := readManifests("-", &out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(HaveLen(3))
+		_, found, err := unstructured.NestedString(objects[2].Object, "spec", "template", "metadata", "annotations", reloader.AnnotationConfigHash)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("should reject workloads not using the content hash mode", func() {
+		manifests := strings.ReplaceAll(renderManifests, "pod-reloader.cs.sap.com/hash-mode: content", "pod-reloader.cs.sap.com/hash-mode: version")
+		Expect(render(ctx, "test", nil, strings.NewReader(manifests), &bytes.Buffer{})).To(MatchError(ContainSubstring("can only be calculated offline")))
+	})
+
+	It("should reject workloads which cannot be rendered offline", func() {
+		manifests := strings.ReplaceAll(renderManifests, "pod-reloader.cs.sap.com/secrets: credentials", "pod-reloader.cs.sap.com/resources: v1/ServiceAccount:app")
+		Expect(render(ctx, "test", nil, strings.NewReader(manifests), &bytes.Buffer{})).To(MatchError(ContainSubstring("cannot be calculated offline")))
+	})
+
+	It("should reject workloads tracking image digests", func() {
+		manifests := strings.ReplaceAll(renderManifests, "pod-reloader.cs.sap.com/hash-mode: content", "pod-reloader.cs.sap.com/hash-mode: content\n    pod-reloader.cs.sap.com/track-image-digests: \"true\"")
+		Expect(render(ctx, "test", nil, strings.NewReader(manifests), &bytes.Buffer{})).To(MatchError(ContainSubstring("cannot be calculated offline")))
+	})
+})
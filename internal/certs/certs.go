@@ -0,0 +1,181 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package certs
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	caValidity      = 5 * 365 * 24 * time.Hour
+	servingValidity = 365 * 24 * time.Hour
+	// certificates are renewed once less than this fraction of their validity is left
+	renewalFraction = 3
+)
+
+// keyPair is a pem encoded certificate with its private key
+type keyPair struct {
+	cert []byte
+	key  []byte
+}
+
+// generate a self-signed ca certificate
+func generateCA(commonName string, now time.Time) (*keyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return encode(der, key)
+}
+
+// generate a serving certificate for the given dns names, signed by the given ca
+func generateServingCert(ca *keyPair, dnsNames []string, now time.Time) (*keyPair, error) {
+	caCert, caKey, err := decode(ca)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+	notAfter := now.Add(servingValidity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	return encode(der, key)
+}
+
+// check if the given (pem encoded) certificate must be renewed, that is, if it cannot be parsed, or if less than
+// a certain fraction of its validity is left
+func needsRenewal(cert []byte, now time.Time) bool {
+	c, err := parseCert(cert)
+	if err != nil {
+		return true
+	}
+	return now.After(c.NotAfter.Add(-c.NotAfter.Sub(c.NotBefore) / renewalFraction))
+}
+
+// check if the given serving certificate is signed by the given ca, and valid for the given dns names
+func verifyServingCert(serving *keyPair, ca *keyPair, dnsNames []string, now time.Time) bool {
+	caCert, err := parseCert(ca.cert)
+	if err != nil {
+		return false
+	}
+	cert, err := parseCert(serving.cert)
+	if err != nil {
+		return false
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	for _, dnsName := range dnsNames {
+		if _, err := cert.Verify(x509.VerifyOptions{DNSName: dnsName, Roots: roots, CurrentTime: now}); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// build a ca bundle from the given (pem encoded) certificates, dropping certificates which are expired or cannot be parsed
+func buildBundle(certs [][]byte, now time.Time) []byte {
+	var bundle bytes.Buffer
+	for _, cert := range certs {
+		for rest := cert; len(rest) > 0; {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			c, err := x509.ParseCertificate(block.Bytes)
+			if err != nil || now.After(c.NotAfter) || bytes.Contains(bundle.Bytes(), pem.EncodeToMemory(block)) {
+				continue
+			}
+			bundle.Write(pem.EncodeToMemory(block))
+		}
+	}
+	return bundle.Bytes()
+}
+
+func newSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func encode(der []byte, key *ecdsa.PrivateKey) (*keyPair, error) {
+	rawKey, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &keyPair{
+		cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: rawKey}),
+	}, nil
+}
+
+func decode(pair *keyPair) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	cert, err := parseCert(pair.cert)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(pair.key)
+	if block == nil {
+		return nil, nil, fmt.Errorf("invalid private key")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func parseCert(cert []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(cert)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("invalid certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,278 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package certs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	keyCACert   = "ca.crt"
+	keyCAKey    = "ca.key"
+	keyCABundle = "ca-bundle.crt"
+	keyTLSCert  = corev1.TLSCertKey
+	keyTLSKey   = corev1.TLSPrivateKeyKey
+)
+
+// interval in which certificates are checked for renewal, and in which certificate files are synced from the secret
+const checkInterval = time.Hour
+
+// Options controls the behavior of the certificate manager.
+type Options struct {
+	// Namespace of the secret holding ca and serving certificate.
+	Namespace string
+	// Name of the secret holding ca and serving certificate.
+	SecretName string
+	// Name of the MutatingWebhookConfiguration whose ca bundle is maintained.
+	WebhookConfigurationName string
+	// DNS names the serving certificate is issued for.
+	DNSNames []string
+	// Directory where the serving certificate is written to, as tls.crt and tls.key.
+	CertDir string
+}
+
+// Manager maintains a self-signed ca and a serving certificate for the webhook server, stored in a secret;
+// the ca bundle of the webhook configuration is kept in sync, and certificates are renewed before they expire
+// (by the leader only); all replicas sync the serving certificate from the secret to the local certificate directory.
+type Manager struct {
+	// uncached client, since the manager is also used before the cache is started
+	client  ctrlclient.Client
+	options Options
+}
+
+func NewManager(client ctrlclient.Client, options Options) *Manager {
+	return &Manager{
+		client:  client,
+		options: options,
+	}
+}
+
+// Bootstrap ensures that the secret holds a usable ca and serving certificate (creating them if necessary),
+// that the ca bundle of the webhook configuration is up to date, and writes the serving certificate to the certificate directory;
+// it is meant to be called synchronously, before the controller manager is started.
+func (m *Manager) Bootstrap(ctx context.Context) error {
+	secret, err := m.ensureSecret(ctx, false, time.Now())
+	if err != nil {
+		return err
+	}
+	return m.writeFiles(secret)
+}
+
+// SetupWithManager adds the periodic renewal (running on the leader only) and file sync (running on all replicas) to the given manager.
+func (m *Manager) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.Add(&renewer{m}); err != nil {
+		return err
+	}
+	return mgr.Add(&syncer{m})
+}
+
+// read the secret, create or renew the contained certificates if necessary, and update the ca bundle of the webhook configuration;
+// if renew is false, certificates are only replaced if they are not usable (e.g. expired); otherwise they are also renewed if they are about to expire
+func (m *Manager) ensureSecret(ctx context.Context, renew bool, now time.Time) (*corev1.Secret, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	for attempt := 0; ; attempt++ {
+		secret := &corev1.Secret{}
+		exists := true
+		if err := m.client.Get(ctx, ctrlclient.ObjectKey{Namespace: m.options.Namespace, Name: m.options.SecretName}, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			exists = false
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: m.options.Namespace,
+					Name:      m.options.SecretName,
+				},
+				Type: corev1.SecretTypeOpaque,
+			}
+		}
+
+		data, changed, err := m.renewCertificates(secret.Data, renew, now)
+		if err != nil {
+			return nil, err
+		}
+
+		// the ca bundle is updated first, such that the new ca is trusted before the serving certificate is replaced
+		if err := m.updateCABundle(ctx, data[keyCABundle]); err != nil {
+			return nil, err
+		}
+		if !changed {
+			return secret, nil
+		}
+
+		log.Info("updating webhook certificates", "namespace", m.options.Namespace, "name", m.options.SecretName)
+		secret.Data = data
+		if exists {
+			err = m.client.Update(ctx, secret)
+		} else {
+			err = m.client.Create(ctx, secret)
+		}
+		if err == nil {
+			return secret, nil
+		}
+		// another replica won the race; retry with the current state of the secret
+		if (apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)) && attempt < 3 {
+			continue
+		}
+		return nil, err
+	}
+}
+
+// compute the new content of the secret; the second return value indicates if anything changed
+func (m *Manager) renewCertificates(data map[string][]byte, renew bool, now time.Time) (map[string][]byte, bool, error) {
+	ca := &keyPair{cert: data[keyCACert], key: data[keyCAKey]}
+	serving := &keyPair{cert: data[keyTLSCert], key: data[keyTLSKey]}
+	changed := false
+
+	caCert, _, err := decode(ca)
+	if err != nil || now.After(caCert.NotAfter) || (renew && needsRenewal(ca.cert, now)) {
+		if ca, err = generateCA(m.options.DNSNames[0]+"-ca", now); err != nil {
+			return nil, false, err
+		}
+		changed = true
+	}
+	if changed || !verifyServingCert(serving, ca, m.options.DNSNames, now) || (renew && needsRenewal(serving.cert, now)) {
+		if serving, err = generateServingCert(ca, m.options.DNSNames, now); err != nil {
+			return nil, false, err
+		}
+		changed = true
+	}
+	// the bundle contains the current ca, and previous cas (as long as they are valid), such that serving certificates
+	// issued by previous cas remain trusted until all replicas have picked up the new serving certificate
+	bundle := buildBundle([][]byte{ca.cert, data[keyCABundle]}, now)
+	if !bytes.Equal(bundle, data[keyCABundle]) {
+		changed = true
+	}
+
+	return map[string][]byte{
+		keyCACert:   ca.cert,
+		keyCAKey:    ca.key,
+		keyCABundle: bundle,
+		keyTLSCert:  serving.cert,
+		keyTLSKey:   serving.key,
+	}, changed, nil
+}
+
+// set the ca bundle of all webhooks of the webhook configuration
+func (m *Manager) updateCABundle(ctx context.Context, bundle []byte) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	webhookConfiguration := &admissionv1.MutatingWebhookConfiguration{}
+	if err := m.client.Get(ctx, ctrlclient.ObjectKey{Name: m.options.WebhookConfigurationName}, webhookConfiguration); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("webhook configuration not found; skipping update of ca bundle", "name", m.options.WebhookConfigurationName)
+			return nil
+		}
+		return err
+	}
+	changed := false
+	for i := range webhookConfiguration.Webhooks {
+		if !bytes.Equal(webhookConfiguration.Webhooks[i].ClientConfig.CABundle, bundle) {
+			webhookConfiguration.Webhooks[i].ClientConfig.CABundle = bundle
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	log.Info("updating ca bundle of webhook configuration", "name", m.options.WebhookConfigurationName)
+	return m.client.Update(ctx, webhookConfiguration)
+}
+
+// write the serving certificate contained in the given secret to the certificate directory (if changed)
+func (m *Manager) writeFiles(secret *corev1.Secret) error {
+	if err := os.MkdirAll(m.options.CertDir, 0o700); err != nil {
+		return err
+	}
+	for _, key := range []string{keyTLSCert, keyTLSKey} {
+		path := filepath.Join(m.options.CertDir, key)
+		if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, secret.Data[key]) {
+			continue
+		}
+		// write atomically, since the webhook server watches these files
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, secret.Data[key], 0o600); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renewer periodically renews the certificates; runs on the leader only
+type renewer struct {
+	m *Manager
+}
+
+var _ manager.LeaderElectionRunnable = &renewer{}
+
+func (r *renewer) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *renewer) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("cert-renewer")
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.m.ensureSecret(ctx, true, time.Now()); err != nil {
+			log.Error(err, "error renewing webhook certificates")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncer periodically writes the serving certificate from the secret to the certificate directory; runs on all replicas
+type syncer struct {
+	m *Manager
+}
+
+var _ manager.LeaderElectionRunnable = &syncer{}
+
+func (s *syncer) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *syncer) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("cert-syncer")
+	// sync more often than the renewal is checked, such that renewed certificates are picked up timely
+	ticker := time.NewTicker(checkInterval / 12)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		secret := &corev1.Secret{}
+		if err := s.m.client.Get(ctx, ctrlclient.ObjectKey{Namespace: s.m.options.Namespace, Name: s.m.options.SecretName}, secret); err != nil {
+			log.Error(err, "error reading webhook certificates")
+			continue
+		}
+		if err := s.m.writeFiles(secret); err != nil {
+			log.Error(err, "error writing webhook certificates")
+		}
+	}
+}
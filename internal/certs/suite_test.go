@@ -0,0 +1,74 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package certs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCerts(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Certs Suite")
+}
+
+var _ = Describe("Test certificate renewal", func() {
+	var m *Manager
+	var now time.Time
+
+	BeforeEach(func() {
+		m = NewManager(nil, Options{DNSNames: []string{"webhook", "webhook.ns.svc"}})
+		now = time.Now()
+	})
+
+	It("should create a ca and a matching serving certificate", func() {
+		data, changed, err := m.renewCertificates(nil, false, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(verifyServingCert(&keyPair{cert: data[keyTLSCert], key: data[keyTLSKey]}, &keyPair{cert: data[keyCACert], key: data[keyCAKey]}, m.options.DNSNames, now)).To(BeTrue())
+		Expect(data[keyCABundle]).To(Equal(data[keyCACert]))
+	})
+
+	It("should keep valid certificates", func() {
+		data, _, err := m.renewCertificates(nil, false, now)
+		Expect(err).NotTo(HaveOccurred())
+		newData, changed, err := m.renewCertificates(data, true, now.Add(24*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(newData).To(Equal(data))
+	})
+
+	It("should renew the serving certificate before it expires", func() {
+		data, _, err := m.renewCertificates(nil, false, now)
+		Expect(err).NotTo(HaveOccurred())
+		later := now.Add(300 * 24 * time.Hour)
+		newData, changed, err := m.renewCertificates(data, false, later)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		newData, changed, err = m.renewCertificates(newData, true, later)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(newData[keyCACert]).To(Equal(data[keyCACert]))
+		Expect(newData[keyTLSCert]).NotTo(Equal(data[keyTLSCert]))
+	})
+
+	It("should keep the previous ca in the bundle when renewing the ca", func() {
+		data, _, err := m.renewCertificates(nil, false, now)
+		Expect(err).NotTo(HaveOccurred())
+		later := now.Add(4 * 365 * 24 * time.Hour)
+		newData, changed, err := m.renewCertificates(data, true, later)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(newData[keyCACert]).NotTo(Equal(data[keyCACert]))
+		Expect(bytes.Contains(newData[keyCABundle], newData[keyCACert])).To(BeTrue())
+		Expect(bytes.Contains(newData[keyCABundle], data[keyCACert])).To(BeTrue())
+		Expect(verifyServingCert(&keyPair{cert: newData[keyTLSCert], key: newData[keyTLSKey]}, &keyPair{cert: newData[keyCACert], key: newData[keyCAKey]}, m.options.DNSNames, later)).To(BeTrue())
+	})
+})
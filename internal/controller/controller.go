@@ -6,17 +6,206 @@ SPDX-License-Identifier: Apache-2.0
 package controller
 
 import (
+	"context"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/sap/pod-reloader/internal/registry"
+	"github.com/sap/pod-reloader/internal/reloader"
 )
 
 const controllerName = "pod-reloader"
 
-func SetupControllerWithManager(mgr ctrl.Manager) error {
-	if err := setupConfigMapHandler(mgr); err != nil {
+// interval after which deferred reloads are retried, if there is no better estimate
+const retryInterval = 10 * time.Second
+
+// Options controls the behavior of the controller.
+type Options struct {
+	// Default minimum interval between two reloads of the same workload triggered by the controller;
+	// may be overridden per workload by the annotation pod-reloader.cs.sap.com/min-reload-interval.
+	MinReloadInterval time.Duration
+	// Maximum number of rollouts triggered by the controller which may be in progress at the same time;
+	// zero means no limit.
+	MaxConcurrentRollouts int
+	// Whether to wait for the current rollout of a workload to complete before triggering another reload;
+	// may be overridden per workload by the annotation pod-reloader.cs.sap.com/wait-for-rollout.
+	WaitForRollout bool
+	// Whether to enable the automatic rollback of configuration for workloads annotated with pod-reloader.cs.sap.com/rollback.
+	EnableRollback bool
+	// Default time after which a rollout caused by a configuration change is considered as failed;
+	// may be overridden per workload by the annotation pod-reloader.cs.sap.com/rollback-timeout.
+	RollbackTimeout time.Duration
+	// Whether to garbage collect unreferenced immutable config map and secret snapshots created by the webhook.
+	EnableImmutableConfig bool
+	// Time to wait after a configuration change before reloading workloads in-place (e.g. by sending a signal),
+	// such that kubelet had the chance to sync the content of mounted volumes.
+	VolumeSyncDelay time.Duration
+	// Whether to call http reload endpoints through the api server's pods/proxy subresource, instead of directly through the pod ip.
+	ReloadViaAPIServerProxy bool
+	// Timeout for a single call of an http reload endpoint.
+	ReloadTimeout time.Duration
+	// Number of retries when calling an http reload endpoint.
+	ReloadRetries int
+	// File containing ca certificates to verify https reload endpoints against (instead of the system roots);
+	// only used when calling endpoints directly.
+	ReloadCAFile string
+	// Whether to skip the verification of the certificates of https reload endpoints; only used when calling endpoints directly.
+	ReloadInsecureSkipVerify bool
+	// Whether to evict pods which are directly annotated with references to config maps or secrets, if these change.
+	EnablePodEviction bool
+	// Default minimum interval between two pod evictions in the same namespace;
+	// may be overridden per pod by the annotation pod-reloader.cs.sap.com/eviction-interval.
+	PodEvictionInterval time.Duration
+	// Whether to reload workloads referencing the same config map or secret in waves, starting with workloads
+	// labeled with pod-reloader.cs.sap.com/canary=true, instead of all at once.
+	EnableStagedReloads bool
+	// Maximum number of workloads reloaded per wave (if staged reloads are enabled); zero means no limit.
+	ReloadBatchSize int
+	// Time after the start of a wave after which the incomplete rollouts of its workloads are considered as failed,
+	// causing the staged reload to be aborted.
+	ReloadWaveTimeout time.Duration
+	// Whether to maintain a ReloadStatus object per namespace, reporting the reload state of the tracked workloads;
+	// requires the ReloadStatus custom resource definition to be installed.
+	EnableReloadStatus bool
+	// If set, reloads are triggered by updating the configuration hash on the pod template through server-side apply
+	// with this field manager (instead of injecting the hash and letting the webhook update the pod template);
+	// must match the setting of the webhook.
+	HashFieldManager string
+	// Namespaces the controller operates in; requests for other namespaces are ignored. Note that restricting the namespaces
+	// by name should in addition be reflected in the manager's cache options, such that other namespaces are not watched at all.
+	NamespaceScope reloader.NamespaceScope
+	// Whether to reload workloads annotated with pod-reloader.cs.sap.com/secretproviderclasses upon rotations reported by
+	// the secrets store csi driver; requires the driver's SecretProviderClassPodStatus custom resource definition to be installed.
+	EnableSecretProviderClasses bool
+	// Whether to reload workloads annotated with pod-reloader.cs.sap.com/resources upon changes of the referenced resources;
+	// watches for the referenced resource types are registered dynamically.
+	EnableResourceReferences bool
+	// Whether to reload workloads annotated with pod-reloader.cs.sap.com/include-implicit-secrets upon changes of their image pull secrets,
+	// their service account, or the secrets referenced by the service account.
+	EnableImplicitSecrets bool
+	// Whether to periodically resolve the digests of the container images of workloads annotated with pod-reloader.cs.sap.com/track-image-digests,
+	// and restart these workloads if the digest of an image changes (e.g. because a mutable tag was re-pushed).
+	EnableImageDigestTracking bool
+	// Interval in which image digests are resolved.
+	ImageDigestInterval time.Duration
+	// Timeout for a single registry request when resolving image digests.
+	RegistryTimeout time.Duration
+	// Whether to run the config map, secret (and secret provider class, resource, service account) handlers on all replicas, splitting the namespaces between them;
+	// replicas are coordinated through leases in ShardLeaseNamespace, identified by ShardIdentity.
+	EnableSharding      bool
+	ShardLeaseNamespace string
+	ShardIdentity       string
+	// Optional constructor for the work queues of the config map, secret (and secret provider class, resource, service account) handlers,
+	// e.g. to track their progress; if unset, the controller-runtime default is used.
+	NewQueue func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request]
+}
+
+func SetupControllerWithManager(mgr ctrl.Manager, options Options) error {
+	executor, err := newPodExecutor(mgr.GetConfig())
+	if err != nil {
 		return err
 	}
-	if err := setupSecretHandler(mgr); err != nil {
+	caller, err := newEndpointCaller(mgr.GetConfig(), options.ReloadViaAPIServerProxy, options.ReloadTimeout, options.ReloadRetries, options.ReloadCAFile, options.ReloadInsecureSkipVerify)
+	if err != nil {
 		return err
 	}
+	h := genericHandler{
+		client:    mgr.GetClient(),
+		reader:    mgr.GetAPIReader(),
+		recorder:  mgr.GetEventRecorderFor(controllerName),
+		options:   options,
+		throttle:  newThrottle(mgr.GetClient(), options.MinReloadInterval, options.MaxConcurrentRollouts),
+		deferrals: newDeferrals(),
+		restarts:  newContainerRestarts(),
+		executor:  executor,
+		caller:    caller,
+	}
+	if options.EnablePodEviction {
+		if err := setupPodReferenceIndex(context.Background(), mgr); err != nil {
+			return err
+		}
+	}
+	if options.EnableStagedReloads {
+		h.stager = newStager(options.ReloadBatchSize, options.ReloadWaveTimeout)
+	}
+	if options.EnableSharding {
+		h.sharder = newSharder(mgr.GetClient(), mgr.GetAPIReader(), options.ShardLeaseNamespace, options.ShardIdentity)
+		if err := mgr.Add(h.sharder); err != nil {
+			return err
+		}
+	}
+	if err := setupConfigMapHandler(mgr, h); err != nil {
+		return err
+	}
+	if err := setupSecretHandler(mgr, h); err != nil {
+		return err
+	}
+	if options.EnableSecretProviderClasses {
+		if err := setupSecretProviderClassHandler(mgr, h); err != nil {
+			return err
+		}
+	}
+	if options.EnableResourceReferences {
+		if err := setupResourceHandler(mgr, h); err != nil {
+			return err
+		}
+	}
+	if options.EnableImplicitSecrets {
+		if err := setupServiceAccountHandler(mgr, h); err != nil {
+			return err
+		}
+	}
+	if options.EnableImageDigestTracking {
+		resolver := registry.NewResolver(&http.Client{Timeout: options.RegistryTimeout})
+		if err := mgr.Add(newDigestTracker(h, resolver, options.ImageDigestInterval)); err != nil {
+			return err
+		}
+	}
+	if options.EnableImmutableConfig {
+		if err := setupSnapshotHandler(mgr, options); err != nil {
+			return err
+		}
+	}
+	if options.EnableReloadStatus {
+		if err := setupStatusHandler(mgr, h); err != nil {
+			return err
+		}
+	}
+	if options.EnableRollback {
+		if err := setupRollbackHandler(mgr, options); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// wrap the given reconciler, such that requests for namespaces outside of the configured scope are ignored
+func scoped(mgr ctrl.Manager, options Options, r reconcile.Reconciler) reconcile.Reconciler {
+	if options.NamespaceScope.IsZero() {
+		return r
+	}
+	return &scopedReconciler{Reconciler: r, client: mgr.GetClient(), scope: options.NamespaceScope}
+}
+
+type scopedReconciler struct {
+	reconcile.Reconciler
+	client ctrlclient.Client
+	scope  reloader.NamespaceScope
+}
+
+func (r *scopedReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	inScope, err := r.scope.Contains(ctx, r.client, request.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !inScope {
+		ctrl.LoggerFrom(ctx).V(1).Info("ignoring request for namespace out of scope")
+		return reconcile.Result{}, nil
+	}
+	return r.Reconciler.Reconcile(ctx, request)
+}
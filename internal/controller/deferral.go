@@ -0,0 +1,62 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deferrals keeps track of the reloads currently deferred by the handlers (and of the reason), such that the status handler
+// can report them; the state is shared between all handlers, and kept in memory
+type deferrals struct {
+	mutex   sync.Mutex
+	entries map[types.UID]deferral
+}
+
+type deferral struct {
+	reason  string
+	message string
+	time    time.Time
+}
+
+func newDeferrals() *deferrals {
+	return &deferrals{entries: make(map[types.UID]deferral)}
+}
+
+// record that the reload of the given workload was deferred
+func (d *deferrals) record(object ctrlclient.Object, reason string, message string, now time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	// deferred reloads are retried (and recorded again) regularly, so old entries most likely belong to deleted workloads
+	for uid, entry := range d.entries {
+		if now.Sub(entry.time) > 24*time.Hour {
+			delete(d.entries, uid)
+		}
+	}
+	d.entries[object.GetUID()] = deferral{reason: reason, message: message, time: now}
+}
+
+// clear the deferral of the given workload, e.g. because it was reloaded
+func (d *deferrals) clear(object ctrlclient.Object) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.entries, object.GetUID())
+}
+
+// return the recorded deferral of the given workload, if any
+func (d *deferrals) get(object ctrlclient.Object) (deferral, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	entry, ok := d.entries[object.GetUID()]
+	return entry, ok
+}
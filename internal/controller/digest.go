@@ -0,0 +1,259 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/sap/pod-reloader/internal/registry"
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+// digestTracker periodically resolves the digests of the container images of workloads annotated with
+// pod-reloader.cs.sap.com/track-image-digests; if the digest of a previously resolved image changes, the image digest
+// revision of the workload is increased, which changes its configuration hash, and a restart is triggered (regardless of the reload strategy)
+type digestTracker struct {
+	genericHandler
+	resolver *registry.Resolver
+	interval time.Duration
+}
+
+var _ manager.Runnable = &digestTracker{}
+var _ manager.LeaderElectionRunnable = &digestTracker{}
+
+func newDigestTracker(h genericHandler, resolver *registry.Resolver, interval time.Duration) *digestTracker {
+	return &digestTracker{genericHandler: h, resolver: resolver, interval: interval}
+}
+
+// if sharding is enabled, the tracker runs on all replicas, and each replica handles the namespaces assigned to it
+func (t *digestTracker) NeedLeaderElection() bool {
+	return t.sharder == nil
+}
+
+func (t *digestTracker) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("digest-tracker")
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := t.track(ctx); err != nil {
+			log.Error(err, "error tracking image digests")
+		}
+	}
+}
+
+func (t *digestTracker) track(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	var objects []ctrlclient.Object
+
+	// add additional workload types here
+	deploymentList := &appsv1.DeploymentList{}
+	if err := t.client.List(ctx, deploymentList); err != nil {
+		return err
+	}
+	for i := range deploymentList.Items {
+		objects = append(objects, &deploymentList.Items[i])
+	}
+	statefulSetList := &appsv1.StatefulSetList{}
+	if err := t.client.List(ctx, statefulSetList); err != nil {
+		return err
+	}
+	for i := range statefulSetList.Items {
+		objects = append(objects, &statefulSetList.Items[i])
+	}
+	daemonSetList := &appsv1.DaemonSetList{}
+	if err := t.client.List(ctx, daemonSetList); err != nil {
+		return err
+	}
+	for i := range daemonSetList.Items {
+		objects = append(objects, &daemonSetList.Items[i])
+	}
+
+	// digests resolved in this round, such that each image is resolved once only
+	resolved := make(map[string]string)
+	// workloads with pending restarts, by namespace and changed image
+	pending := make(map[string]map[string][]ctrlclient.Object)
+	for _, object := range objects {
+		if !reloader.TracksImageDigests(object.GetAnnotations()) {
+			continue
+		}
+		if inScope, err := t.options.NamespaceScope.Contains(ctx, t.client, object.GetNamespace()); err != nil {
+			return err
+		} else if !inScope {
+			continue
+		}
+		if t.sharder != nil && !t.sharder.owns(object.GetNamespace()) {
+			continue
+		}
+		gvk, err := apiutil.GVKForObject(object, t.client.Scheme())
+		if err != nil {
+			return err
+		}
+		log := log.WithValues("kind", gvk.Kind, "namespace", object.GetNamespace(), "name", object.GetName())
+		changedImage, err := t.trackObject(ctrl.LoggerInto(ctx, log), object, resolved)
+		if err != nil {
+			log.Error(err, "error tracking image digests of workload")
+			continue
+		}
+		if changedImage != "" {
+			if pending[object.GetNamespace()] == nil {
+				pending[object.GetNamespace()] = make(map[string][]ctrlclient.Object)
+			}
+			pending[object.GetNamespace()][changedImage] = append(pending[object.GetNamespace()][changedImage], object)
+		}
+	}
+
+	// restarts are triggered through the same code path as reloads due to configuration changes (thus respecting staging, priorities,
+	// maintenance windows, rollout status and throttling); the changed digests are only recorded together with the restart, such that
+	// deferred restarts are detected again in the next round
+	now := time.Now()
+	for namespace, images := range pending {
+		for image, objects := range images {
+			if _, err := t.reloadObjects(ctx, "Image", namespace, image, objects, true, now); err != nil {
+				log.Error(err, "error restarting workloads due to changed image digest", "namespace", namespace, "image", image)
+			}
+		}
+	}
+	return nil
+}
+
+// resolve the image digests of the given workload; if no previously resolved digest has changed, new digests are recorded on the workload;
+// otherwise, the changed image is returned, and the workload is updated in memory only (recording the new digests, and increasing the image
+// digest revision, and thus the configuration hash), such that the caller can trigger the restart
+func (t *digestTracker) trackObject(ctx context.Context, object ctrlclient.Object, resolved map[string]string) (string, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	podTemplate := reloader.PodTemplate(object)
+	if podTemplate == nil {
+		return "", nil
+	}
+	annotations := object.GetAnnotations()
+
+	previous := make(map[string]string)
+	if annotations[reloader.AnnotationImageDigests] != "" {
+		if err := json.Unmarshal([]byte(annotations[reloader.AnnotationImageDigests]), &previous); err != nil {
+			log.Error(err, "ignoring invalid image digests annotation")
+		}
+	}
+
+	keychain, err := t.keychain(ctx, object.GetNamespace(), &podTemplate.Spec)
+	if err != nil {
+		return "", err
+	}
+
+	digests := make(map[string]string)
+	var changedImage string
+	// note: a new slice is allocated, since the pod template belongs to the cache
+	containers := make([]corev1.Container, 0, len(podTemplate.Spec.InitContainers)+len(podTemplate.Spec.Containers))
+	containers = append(containers, podTemplate.Spec.InitContainers...)
+	containers = append(containers, podTemplate.Spec.Containers...)
+	for _, container := range containers {
+		image := container.Image
+		if _, ok := digests[image]; ok {
+			continue
+		}
+		digest, ok := resolved[image]
+		if !ok {
+			var err error
+			if digest, err = t.resolver.ResolveDigest(ctx, image, keychain); err != nil {
+				log.Error(err, "error resolving image digest", "image", image)
+				t.recorder.Eventf(object, corev1.EventTypeWarning, "ImageDigestResolutionFailed", "Error resolving digest of image %s: %s", image, err)
+				// keep the previously resolved digest, if any
+				if previousDigest, ok := previous[image]; ok {
+					digests[image] = previousDigest
+				}
+				continue
+			}
+			resolved[image] = digest
+		}
+		digests[image] = digest
+		// images resolved for the first time (e.g. because the image was changed) do not trigger a restart
+		if previousDigest, ok := previous[image]; ok && previousDigest != digest {
+			changedImage = image
+		}
+	}
+	if maps.Equal(digests, previous) {
+		return "", nil
+	}
+
+	rawDigests, err := json.Marshal(digests)
+	if err != nil {
+		return "", err
+	}
+	oldObject := object.DeepCopyObject().(ctrlclient.Object)
+	annotations[reloader.AnnotationImageDigests] = string(rawDigests)
+	if changedImage != "" {
+		log.Info("image digest changed; requesting restart", "image", changedImage)
+		revision, _ := strconv.Atoi(annotations[reloader.AnnotationImageDigestRevision])
+		annotations[reloader.AnnotationImageDigestRevision] = strconv.Itoa(revision + 1)
+		object.SetAnnotations(annotations)
+		return changedImage, nil
+	}
+	log.V(1).Info("recording image digests")
+	object.SetAnnotations(annotations)
+	return "", t.client.Patch(ctx, object, ctrlclient.MergeFrom(oldObject))
+}
+
+// build a keychain from the image pull secrets of the given pod spec and of its service account (if existing)
+func (t *digestTracker) keychain(ctx context.Context, namespace string, podSpec *corev1.PodSpec) (registry.Keychain, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var secretNames []string
+	for _, ref := range podSpec.ImagePullSecrets {
+		secretNames = append(secretNames, ref.Name)
+	}
+	serviceAccount := &corev1.ServiceAccount{}
+	if err := t.client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: reloader.ServiceAccountName(podSpec)}, serviceAccount); err == nil {
+		for _, ref := range serviceAccount.ImagePullSecrets {
+			secretNames = append(secretNames, ref.Name)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	keychain := registry.Keychain{}
+	for _, secretName := range secretNames {
+		secret := &corev1.Secret{}
+		if err := t.client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: secretName}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		var data []byte
+		switch secret.Type {
+		case corev1.SecretTypeDockerConfigJson:
+			data = secret.Data[corev1.DockerConfigJsonKey]
+		case corev1.SecretTypeDockercfg:
+			data = secret.Data[corev1.DockerConfigKey]
+		default:
+			continue
+		}
+		if err := keychain.AddDockerConfig(data); err != nil {
+			log.Error(err, "ignoring invalid image pull secret", "secret", secretName)
+		}
+	}
+	return keychain, nil
+}
@@ -0,0 +1,122 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/registry"
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test image digest tracking", func() {
+	var server *httptest.Server
+	var image string
+	var deployment *appsv1.Deployment
+	var pullSecret *corev1.Secret
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v2/tools/private/manifests/stable", func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:2222")
+		})
+		server = httptest.NewTLSServer(mux)
+		host := strings.TrimPrefix(server.URL, "https://")
+		image = host + "/tools/private:stable"
+
+		pullSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pull"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{"` + host + `":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("user:pass")) + `"}}}`),
+			},
+		}
+		deployment = buildDeployment("test", "test", map[string]string{
+			reloader.AnnotationTrackImageDigests: "true",
+			reloader.AnnotationImageDigests:      `{"` + image + `":"sha256:1111"}`,
+		})
+		deployment.Spec.Template.Spec.Containers[0].Image = image
+		deployment.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull"}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newTestTracker := func(objects ...ctrlclient.Object) *digestTracker {
+		return newDigestTracker(newTestHandler(Options{}, objects...), registry.NewResolver(server.Client()), 0)
+	}
+
+	It("should resolve digests with image pull secrets, and restart workloads with changed digests", func() {
+		t := newTestTracker(deployment, pullSecret)
+		Expect(t.track(ctx)).To(Succeed())
+
+		hash := injectedHash(t.genericHandler, deployment)
+		Expect(hash).NotTo(BeEmpty())
+		Expect(deployment.Annotations[reloader.AnnotationImageDigestRevision]).To(Equal("1"))
+		Expect(deployment.Annotations[reloader.AnnotationImageDigests]).To(ContainSubstring("sha256:2222"))
+		Expect(reloader.GenerateHashForObject(ctx, t.client, deployment)).To(Equal(hash))
+	})
+
+	It("should use image pull secrets of the service account", func() {
+		deployment.Spec.Template.Spec.ImagePullSecrets = nil
+		deployment.Spec.Template.Spec.ServiceAccountName = "app"
+		serviceAccount := &corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Namespace: "test", Name: "app"},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "pull"}},
+		}
+		t := newTestTracker(deployment, pullSecret, serviceAccount)
+		Expect(t.track(ctx)).To(Succeed())
+		Expect(injectedHash(t.genericHandler, deployment)).NotTo(BeEmpty())
+	})
+
+	It("should not record digests without credentials", func() {
+		t := newTestTracker(deployment)
+		Expect(t.track(ctx)).To(Succeed())
+		Expect(injectedHash(t.genericHandler, deployment)).To(BeEmpty())
+		Expect(deployment.Annotations[reloader.AnnotationImageDigests]).To(ContainSubstring("sha256:1111"))
+	})
+
+	It("should restart workloads with in-place reload strategies", func() {
+		deployment.Annotations[reloader.AnnotationStrategy] = reloader.StrategySignal
+		t := newTestTracker(deployment, pullSecret)
+		Expect(t.track(ctx)).To(Succeed())
+		Expect(injectedHash(t.genericHandler, deployment)).NotTo(BeEmpty())
+	})
+
+	It("should defer restarts outside of maintenance windows, without recording the changed digests", func() {
+		deployment.Annotations[reloader.AnnotationMaintenanceWindows] = inactiveMaintenanceWindow()
+		t := newTestTracker(deployment, pullSecret)
+		Expect(t.track(ctx)).To(Succeed())
+		Expect(injectedHash(t.genericHandler, deployment)).To(BeEmpty())
+		Expect(deployment.Annotations[reloader.AnnotationImageDigests]).To(ContainSubstring("sha256:1111"))
+		Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationImageDigestRevision))
+	})
+
+	It("should defer restarts until the current rollout has completed, if requested", func() {
+		deployment.Annotations[reloader.AnnotationWaitForRollout] = "true"
+		deployment.Status.ObservedGeneration = 0
+		t := newTestTracker(deployment, pullSecret)
+		Expect(t.track(ctx)).To(Succeed())
+		Expect(injectedHash(t.genericHandler, deployment)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,185 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+// name of the index on the (metadata-only) pod cache, containing the references of pods to config maps, secrets, etc.
+const podReferenceIndex = "podReferences"
+
+// reference annotations of pods which are indexed
+var podReferenceAnnotations = []string{
+	reloader.AnnotationConfigMaps,
+	reloader.AnnotationSecrets,
+	reloader.AnnotationSecretProviderClasses,
+	reloader.AnnotationResources,
+}
+
+// return an empty pod metadata object, as used for the metadata-only pod cache
+func newPodMetadata() *metav1.PartialObjectMetadata {
+	podMetadata := &metav1.PartialObjectMetadata{}
+	podMetadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Pod"))
+	return podMetadata
+}
+
+// extract the index values of the given pod (metadata); these are of the form <annotation>=<name>; pods including implicit secrets
+// are additionally indexed by the according annotation, because their references can only be determined from the full pod
+func indexPodReferences(object ctrlclient.Object) []string {
+	annotations := object.GetAnnotations()
+	var values []string
+	for _, annotation := range podReferenceAnnotations {
+		for _, name := range reloader.SplitNames(annotations[annotation]) {
+			values = append(values, annotation+"="+name)
+		}
+	}
+	if reloader.IncludesImplicitSecrets(annotations) {
+		values = append(values, reloader.AnnotationIncludeImplicitSecrets)
+	}
+	return values
+}
+
+// register the index used to find pods referencing a given object
+func setupPodReferenceIndex(ctx context.Context, mgr ctrl.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(ctx, newPodMetadata(), podReferenceIndex, indexPodReferences)
+}
+
+// return the pods of the given namespace which might reference the given object, according to the handler's annotation;
+// candidates are found through the metadata-only pod cache (to avoid caching all pods of the cluster), and then read uncached
+func (h *genericHandler) referencingPods(ctx context.Context, namespace string, name string) ([]*corev1.Pod, error) {
+	values := []string{h.annotation + "=" + name}
+	if h.options.EnableImplicitSecrets && (h.annotation == reloader.AnnotationSecrets || h.annotation == reloader.AnnotationIncludeImplicitSecrets) {
+		values = append(values, reloader.AnnotationIncludeImplicitSecrets)
+	}
+	var pods []*corev1.Pod
+	seen := make(map[string]bool)
+	for _, value := range values {
+		podMetadataList := &metav1.PartialObjectMetadataList{}
+		podMetadataList.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PodList"))
+		if err := h.client.List(ctx, podMetadataList, ctrlclient.InNamespace(namespace), ctrlclient.MatchingFields{podReferenceIndex: value}); err != nil {
+			return nil, err
+		}
+		for _, podMetadata := range podMetadataList.Items {
+			if seen[podMetadata.Name] || podMetadata.DeletionTimestamp != nil {
+				continue
+			}
+			seen[podMetadata.Name] = true
+			pod := &corev1.Pod{}
+			if err := h.reader.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: podMetadata.Name}, pod); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// evict pods which are directly annotated with a reference to the given object, and which are running with an outdated
+// configuration hash (as set by the webhook upon pod creation); pods of a namespace are evicted one at a time, paced by
+// the configured eviction interval; a positive duration is returned if the request should be requeued
+func (h *genericHandler) handlePods(ctx context.Context, kind string, namespace string, name string, now time.Time) (time.Duration, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	pods, err := h.referencingPods(ctx, namespace, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var requeueAfter time.Duration
+	var stalePods []*corev1.Pod
+
+	for _, pod := range pods {
+		annotations := pod.Annotations
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		names, err := h.references(ctx, pod)
+		if err != nil {
+			return 0, err
+		}
+		if !slices.Contains(names, name) {
+			continue
+		}
+		log := log.WithValues("kind", "Pod", "namespace", pod.Namespace, "name", pod.Name)
+		ctx := ctrl.LoggerInto(ctx, log)
+		metricLabels := []string{"Pod", pod.Namespace, pod.Name}
+
+		hash, err := reloader.GenerateHashForObject(ctx, h.client, pod)
+		if err != nil {
+			return 0, err
+		}
+		if annotations[reloader.AnnotationConfigHash] == hash {
+			log.V(1).Info("configuration hash is up to date")
+			pendingReloads.DeleteLabelValues(metricLabels...)
+			continue
+		}
+
+		pendingReloads.WithLabelValues(metricLabels...).Set(1)
+		if deferred, delay := h.checkMaintenanceWindows(ctx, pod, kind, namespace, name, now); deferred {
+			requeueAfter = minDuration(requeueAfter, delay)
+			continue
+		}
+		stalePods = append(stalePods, pod)
+	}
+
+	if len(stalePods) == 0 {
+		return requeueAfter, nil
+	}
+	if delay := h.throttle.evictionDelay(namespace, now); delay > 0 {
+		log.V(1).Info("deferring pod eviction due to eviction interval", "delay", delay)
+		return minDuration(requeueAfter, delay), nil
+	}
+
+	sort.Slice(stalePods, func(i, j int) bool { return stalePods[i].Name < stalePods[j].Name })
+	pod := stalePods[0]
+	interval := h.options.PodEvictionInterval
+	if s := pod.Annotations[reloader.AnnotationEvictionInterval]; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			h.recorder.Eventf(pod, corev1.EventTypeWarning, "InvalidEvictionInterval", "Invalid eviction interval: %s", err)
+		} else {
+			interval = d
+		}
+	}
+
+	log.Info("evicting pod", "pod", pod.Name)
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name}}
+	if err := h.client.SubResource("eviction").Create(ctx, pod, eviction); err != nil {
+		if apierrors.IsNotFound(err) {
+			return minDuration(requeueAfter, retryInterval), nil
+		}
+		if apierrors.IsTooManyRequests(err) {
+			// eviction blocked by a pod disruption budget
+			log.Info("pod eviction currently not allowed; retrying later", "pod", pod.Name, "error", err.Error())
+			return minDuration(requeueAfter, minDuration(interval, retryInterval)), nil
+		}
+		return 0, err
+	}
+	h.throttle.recordEviction(namespace, interval, now)
+	pendingReloads.DeleteLabelValues("Pod", pod.Namespace, pod.Name)
+	h.recorder.Eventf(pod, corev1.EventTypeNormal, "ConfigurationChanged", "Pod evicted due to change of referenced %s %s/%s", kind, namespace, name)
+
+	if len(stalePods) > 1 {
+		requeueAfter = minDuration(requeueAfter, minDuration(interval, retryInterval))
+	}
+	return requeueAfter, nil
+}
@@ -0,0 +1,154 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test pod eviction", func() {
+	var configMap *corev1.ConfigMap
+	var pods []*corev1.Pod
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "value"},
+		}
+		pods = nil
+		for _, name := range []string{"pod-1", "pod-2"} {
+			pod := buildPod("test", name, nil, true, "app")
+			pod.Annotations = map[string]string{
+				reloader.AnnotationConfigMaps: "config",
+				reloader.AnnotationConfigHash: "stale",
+			}
+			pods = append(pods, pod)
+		}
+	})
+
+	newEvictionHandler := func(options Options, objects ...ctrlclient.Object) *configMapHandler {
+		options.EnablePodEviction = true
+		return newConfigMapHandler(newTestHandler(options, append([]ctrlclient.Object{configMap}, objects...)...))
+	}
+
+	podExists := func(h *configMapHandler, pod *corev1.Pod) bool {
+		err := h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(pod), &corev1.Pod{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return true
+	}
+
+	It("should evict stale pods one at a time, paced by the eviction interval", func() {
+		h := newEvictionHandler(Options{PodEvictionInterval: time.Minute}, pods[0], pods[1])
+
+		requeueAfter, err := h.handle(ctx, "ConfigMap", "test", "config")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(Equal(minDuration(time.Minute, retryInterval)))
+		Expect(podExists(h, pods[0])).To(BeFalse())
+		Expect(podExists(h, pods[1])).To(BeTrue())
+
+		requeueAfter, err = h.handle(ctx, "ConfigMap", "test", "config")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(BeNumerically(">", 0))
+		Expect(requeueAfter).To(BeNumerically("<=", time.Minute))
+		Expect(podExists(h, pods[1])).To(BeTrue())
+	})
+
+	It("should not evict pods with an up-to-date configuration hash", func() {
+		h := newEvictionHandler(Options{}, pods[0])
+		hash, err := reloader.GenerateHashForObject(ctx, h.client, pods[0])
+		Expect(err).NotTo(HaveOccurred())
+		pods[0].Annotations[reloader.AnnotationConfigHash] = hash
+		Expect(h.client.Update(ctx, pods[0])).To(Succeed())
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(podExists(h, pods[0])).To(BeTrue())
+	})
+
+	It("should not evict pods if pod eviction is disabled", func() {
+		h := newConfigMapHandler(newTestHandler(Options{}, configMap, pods[0]))
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(podExists(h, pods[0])).To(BeTrue())
+	})
+
+	It("should retry evictions blocked by pod disruption budgets", func() {
+		h := newEvictionHandler(Options{}, pods[0])
+		h.client = interceptor.NewClient(h.client.(ctrlclient.WithWatch), interceptor.Funcs{
+			SubResourceCreate: func(ctx context.Context, client ctrlclient.Client, subResourceName string, obj ctrlclient.Object, subResource ctrlclient.Object, opts ...ctrlclient.SubResourceCreateOption) error {
+				return apierrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 0)
+			},
+		})
+
+		requeueAfter, err := h.handle(ctx, "ConfigMap", "test", "config")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(Equal(retryInterval))
+		Expect(podExists(h, pods[0])).To(BeTrue())
+	})
+
+	It("should read only pods referencing the changed object", func() {
+		other := buildPod("test", "other", nil, true, "app")
+		other.Annotations = map[string]string{
+			reloader.AnnotationConfigMaps: "other",
+			reloader.AnnotationConfigHash: "stale",
+		}
+		h := newEvictionHandler(Options{}, pods[0], other)
+		var reads []string
+		h.reader = interceptor.NewClient(h.client.(ctrlclient.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, client ctrlclient.WithWatch, key ctrlclient.ObjectKey, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+				if _, ok := obj.(*corev1.Pod); ok {
+					reads = append(reads, key.Name)
+				}
+				return client.Get(ctx, key, obj, opts...)
+			},
+		})
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(reads).To(ConsistOf("pod-1"))
+		Expect(podExists(h, pods[0])).To(BeFalse())
+		Expect(podExists(h, other)).To(BeTrue())
+	})
+
+	It("should evict pods referencing changed implicit secrets", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pull-secret"}}
+		pod := buildPod("test", "pod", nil, true, "app")
+		pod.Annotations = map[string]string{
+			reloader.AnnotationIncludeImplicitSecrets: "true",
+			reloader.AnnotationConfigHash:             "stale",
+		}
+		pod.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull-secret"}}
+		h := newSecretHandler(newTestHandler(Options{EnablePodEviction: true, EnableImplicitSecrets: true}, secret, pod))
+
+		Expect(h.handle(ctx, "Secret", "test", "pull-secret")).To(BeZero())
+		Expect(h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(pod), &corev1.Pod{})).NotTo(Succeed())
+	})
+
+	DescribeTable("indexing pod references",
+		func(annotations map[string]string, expected []string) {
+			podMetadata := newPodMetadata()
+			podMetadata.Annotations = annotations
+			Expect(indexPodReferences(podMetadata)).To(Equal(expected))
+		},
+		Entry("no references", nil, nil),
+		Entry("config maps and secrets", map[string]string{reloader.AnnotationConfigMaps: "a,b", reloader.AnnotationSecrets: "c"},
+			[]string{reloader.AnnotationConfigMaps + "=a", reloader.AnnotationConfigMaps + "=b", reloader.AnnotationSecrets + "=c"}),
+		Entry("implicit secrets", map[string]string{reloader.AnnotationIncludeImplicitSecrets: "true"},
+			[]string{reloader.AnnotationIncludeImplicitSecrets}),
+	)
+})
@@ -0,0 +1,82 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// commandExecutor runs commands in containers
+type commandExecutor interface {
+	// run the given command in the specified container, and return its standard output
+	exec(ctx context.Context, namespace string, podName string, containerName string, command []string) (string, error)
+}
+
+// podExecutor runs commands in containers through the pods/exec subresource
+type podExecutor struct {
+	config    *rest.Config
+	clientset kubernetes.Interface
+}
+
+var _ commandExecutor = &podExecutor{}
+
+func newPodExecutor(config *rest.Config) (*podExecutor, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &podExecutor{config: config, clientset: clientset}, nil
+}
+
+// run the given command in the specified container, and return its standard output
+func (e *podExecutor) exec(ctx context.Context, namespace string, podName string, containerName string, command []string) (string, error) {
+	req := e.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: containerName,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	// same as kubectl: prefer websockets, fall back to spdy if the api server does not support websockets
+	websocketExecutor, err := remotecommand.NewWebSocketExecutor(e.config, "GET", req.URL().String())
+	if err != nil {
+		return "", err
+	}
+	spdyExecutor, err := remotecommand.NewSPDYExecutor(e.config, "POST", req.URL())
+	if err != nil {
+		return "", err
+	}
+	executor, err := remotecommand.NewFallbackExecutor(websocketExecutor, spdyExecutor, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		if s := strings.TrimSpace(stderr.String()); s != "" {
+			return "", fmt.Errorf("%w: %s", err, s)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
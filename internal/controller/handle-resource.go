@@ -0,0 +1,180 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const resourceHandlerName = "resource-handler"
+
+// handler for arbitrary resources referenced through pod-reloader.cs.sap.com/resources; requests are named
+// by the (formatted) resource reference, which is matched against the references of the workloads
+type resourceHandler struct {
+	genericHandler
+	mgr        ctrl.Manager
+	controller controller.Controller
+	mutex      sync.Mutex
+	watched    map[schema.GroupVersionKind]bool
+	pending    []schema.GroupVersionKind
+	wakeup     chan struct{}
+}
+
+var _ reconcile.Reconciler = &resourceHandler{}
+var _ manager.LeaderElectionRunnable = &resourceHandler{}
+
+func newResourceHandler(mgr ctrl.Manager, h genericHandler) *resourceHandler {
+	h.annotation = reloader.AnnotationResources
+	return &resourceHandler{genericHandler: h, mgr: mgr, watched: make(map[schema.GroupVersionKind]bool), wakeup: make(chan struct{}, 1)}
+}
+
+// watches for referenced resource types are registered dynamically, when they are first referenced by a workload;
+// since controller.Watch blocks while the controller is starting (and waiting for its sources to sync), the event handlers
+// only queue the resource types, and the watches are registered by the handler's runnable
+func setupResourceHandler(mgr ctrl.Manager, h genericHandler) error {
+	r := newResourceHandler(mgr, h)
+	if err := mgr.Add(r); err != nil {
+		return err
+	}
+	c, err := controller.New(resourceHandlerName, mgr, controller.Options{Reconciler: scoped(mgr, h.options, r), MaxConcurrentReconciles: 5, NeedLeaderElection: ptr.To(h.sharder == nil), NewQueue: h.options.NewQueue})
+	if err != nil {
+		return err
+	}
+	r.controller = c
+
+	toReferences := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object ctrlclient.Object) []reconcile.Request {
+		return r.resourceRequests(ctx, object)
+	})
+	registerWatches := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object ctrlclient.Object) []reconcile.Request {
+		requests := r.resourceRequests(ctx, object)
+		if h.options.HashFieldManager == "" {
+			// the webhook takes care of workload changes
+			return nil
+		}
+		return requests
+	})
+	// add additional workload types here
+	for _, object := range []ctrlclient.Object{&appsv1.Deployment{}, &appsv1.StatefulSet{}, &appsv1.DaemonSet{}} {
+		if err := c.Watch(source.Kind(mgr.GetCache(), object, registerWatches)); err != nil {
+			return err
+		}
+	}
+	if h.sharder != nil {
+		// add additional workload types here
+		for _, newList := range []func() ctrlclient.ObjectList{
+			func() ctrlclient.ObjectList { return &appsv1.DeploymentList{} },
+			func() ctrlclient.ObjectList { return &appsv1.StatefulSetList{} },
+			func() ctrlclient.ObjectList { return &appsv1.DaemonSetList{} },
+		} {
+			if err := c.Watch(h.sharder.resyncSource(newList, toReferences)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// return requests for the resources referenced by the given workload, and queue the referenced resource types for being watched
+func (r *resourceHandler) resourceRequests(ctx context.Context, object ctrlclient.Object) []reconcile.Request {
+	log := ctrl.LoggerFrom(ctx)
+
+	var requests []reconcile.Request
+	for _, reference := range reloader.SplitNames(object.GetAnnotations()[reloader.AnnotationResources]) {
+		gvk, name, err := reloader.ParseResourceReference(reference)
+		if err != nil {
+			log.Error(err, "ignoring invalid resource reference", "namespace", object.GetNamespace(), "name", object.GetName())
+			continue
+		}
+		r.requestWatch(gvk)
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: object.GetNamespace(), Name: reloader.FormatResourceReference(gvk, name)}})
+	}
+	return requests
+}
+
+// queue the given resource type for being watched (unless it is already watched or queued); must not block,
+// since it is called from event handlers
+func (r *resourceHandler) requestWatch(gvk schema.GroupVersionKind) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.watched[gvk] {
+		return
+	}
+	r.watched[gvk] = true
+	r.pending = append(r.pending, gvk)
+	select {
+	case r.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (r *resourceHandler) NeedLeaderElection() bool {
+	// controller.Watch just records the source if the controller is not (yet) started
+	return false
+}
+
+// register the queued watches; failed registrations are dropped from the watched types, such that they are
+// requested again by the next event of a referencing workload
+func (r *resourceHandler) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName(resourceHandlerName)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.wakeup:
+		}
+		r.mutex.Lock()
+		pending := r.pending
+		r.pending = nil
+		r.mutex.Unlock()
+		for _, gvk := range pending {
+			log.Info("watching referenced resource type", "gvk", gvk)
+			if err := r.watch(gvk); err != nil {
+				log.Error(err, "error watching referenced resource type", "gvk", gvk)
+				r.mutex.Lock()
+				delete(r.watched, gvk)
+				r.mutex.Unlock()
+			}
+		}
+	}
+}
+
+func (r *resourceHandler) watch(gvk schema.GroupVersionKind) error {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+	toReference := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object ctrlclient.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: object.GetNamespace(), Name: reloader.FormatResourceReference(gvk, object.GetName())}}}
+	})
+	return r.controller.Watch(source.Kind(r.mgr.GetCache(), ctrlclient.Object(object), toReference))
+}
+
+func (r *resourceHandler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	gvk, _, err := reloader.ParseResourceReference(request.Name)
+	if err != nil {
+		return reconcile.Result{}, reconcile.TerminalError(err)
+	}
+	requeueAfter, err := r.handle(ctx, gvk.Kind, request.Namespace, request.Name)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
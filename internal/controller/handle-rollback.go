@@ -0,0 +1,363 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const rollbackHandlerName = "rollback-handler"
+
+const (
+	rollbackConfigMaps = "configmaps"
+	rollbackAll        = "all"
+)
+
+// annotations maintained on the snapshot secrets
+const (
+	annotationRolloutHash    = "pod-reloader.cs.sap.com/rollout-hash"
+	annotationRolloutStarted = "pod-reloader.cs.sap.com/rollout-started"
+)
+
+// rollbackHandler maintains a snapshot of the referenced configuration of workloads annotated with
+// pod-reloader.cs.sap.com/rollback, taken whenever a rollout has completed successfully; if a rollout
+// caused by a configuration change fails, the referenced config maps (and optionally secrets) are restored
+// from the snapshot, which in turn triggers a reload with the previous configuration; note that decisions are based on the content of
+// the referenced objects (compared with the content of the snapshot), rather than on configuration hashes, since the latter may
+// depend on metadata (such as resource versions), and therefore change when the content is restored
+type rollbackHandler struct {
+	client    ctrlclient.Client
+	recorder  record.EventRecorder
+	options   Options
+	newObject func() ctrlclient.Object
+}
+
+var _ reconcile.Reconciler = &rollbackHandler{}
+
+type snapshotEntry struct {
+	Data       map[string]string `json:"data,omitempty"`
+	BinaryData map[string][]byte `json:"binaryData,omitempty"`
+}
+
+func setupRollbackHandler(mgr ctrl.Manager, options Options) error {
+	// add additional workload types here
+	for _, newObject := range []func() ctrlclient.Object{
+		func() ctrlclient.Object { return &appsv1.Deployment{} },
+		func() ctrlclient.Object { return &appsv1.StatefulSet{} },
+		func() ctrlclient.Object { return &appsv1.DaemonSet{} },
+	} {
+		gvk, err := apiutil.GVKForObject(newObject(), mgr.GetScheme())
+		if err != nil {
+			return err
+		}
+		h := &rollbackHandler{
+			client:    mgr.GetClient(),
+			recorder:  mgr.GetEventRecorderFor(controllerName),
+			options:   options,
+			newObject: newObject,
+		}
+		c, err := controller.New(strings.ToLower(gvk.Kind)+"-"+rollbackHandlerName, mgr, controller.Options{Reconciler: scoped(mgr, options, h), MaxConcurrentReconciles: 5})
+		if err != nil {
+			return err
+		}
+		hasRollbackAnnotation := func(object ctrlclient.Object) bool {
+			return object.GetAnnotations()[reloader.AnnotationRollback] != ""
+		}
+		// updates removing the annotation must pass as well, such that the snapshot gets deleted
+		if err := c.Watch(source.Kind(mgr.GetCache(), newObject(), &handler.EnqueueRequestForObject{}, predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool { return hasRollbackAnnotation(e.Object) },
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return hasRollbackAnnotation(e.ObjectOld) || hasRollbackAnnotation(e.ObjectNew)
+			},
+			DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+			GenericFunc: func(e event.GenericEvent) bool { return hasRollbackAnnotation(e.Object) },
+		})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *rollbackHandler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	object := h.newObject()
+	if err := h.client.Get(ctx, request.NamespacedName, object); err != nil {
+		return reconcile.Result{}, ctrlclient.IgnoreNotFound(err)
+	}
+	gvk, err := apiutil.GVKForObject(object, h.client.Scheme())
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	snapshot := &corev1.Secret{}
+	snapshotExists := true
+	if err := h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: object.GetNamespace(), Name: rollbackSecretName(gvk.Kind, object.GetName())}, snapshot); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		snapshotExists = false
+	}
+
+	mode := object.GetAnnotations()[reloader.AnnotationRollback]
+	if mode != rollbackConfigMaps && mode != rollbackAll {
+		if mode != "" {
+			h.recorder.Eventf(object, corev1.EventTypeWarning, "InvalidRollback", "Invalid value for annotation %s: %s", reloader.AnnotationRollback, mode)
+		}
+		if snapshotExists {
+			log.Info("deleting configuration snapshot")
+			return reconcile.Result{}, ctrlclient.IgnoreNotFound(h.client.Delete(ctx, snapshot))
+		}
+		return reconcile.Result{}, nil
+	}
+
+	podTemplate := reloader.PodTemplate(object)
+	if podTemplate == nil || podTemplate.Annotations[reloader.AnnotationConfigHash] == "" {
+		return reconcile.Result{}, nil
+	}
+	deployedHash := podTemplate.Annotations[reloader.AnnotationConfigHash]
+
+	hash, err := reloader.GenerateHashForObject(ctx, h.client, object)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if rolloutComplete(object) && !progressDeadlineExceeded(object) {
+		if hash != deployedHash {
+			// configuration changed again, and the according reload is not yet triggered; the current content is not what the pods are running
+			return reconcile.Result{}, nil
+		}
+		data, err := h.snapshotData(ctx, object, mode == rollbackAll)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if snapshotExists && maps.EqualFunc(snapshot.Data, data, bytes.Equal) {
+			return reconcile.Result{}, nil
+		}
+		log.Info("taking configuration snapshot", "hash", deployedHash)
+		return reconcile.Result{}, h.takeSnapshot(ctx, object, gvk.Kind, data, deployedHash)
+	}
+
+	if !snapshotExists {
+		// nothing to roll back to
+		return reconcile.Result{}, nil
+	}
+	data, err := h.snapshotData(ctx, object, mode == rollbackAll)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if maps.EqualFunc(snapshot.Data, data, bytes.Equal) {
+		// rollout not caused by a configuration change, or rollback already done
+		return reconcile.Result{}, nil
+	}
+
+	now := time.Now()
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = make(map[string]string)
+	}
+	if snapshot.Annotations[annotationRolloutHash] != deployedHash {
+		snapshot.Annotations[annotationRolloutHash] = deployedHash
+		snapshot.Annotations[annotationRolloutStarted] = now.UTC().Format(time.RFC3339)
+		if err := h.client.Update(ctx, snapshot); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	started, err := time.Parse(time.RFC3339, snapshot.Annotations[annotationRolloutStarted])
+	if err != nil {
+		started = now
+	}
+
+	timeout := h.options.RollbackTimeout
+	if s := object.GetAnnotations()[reloader.AnnotationRollbackTimeout]; s != "" {
+		if timeout, err = time.ParseDuration(s); err != nil {
+			h.recorder.Eventf(object, corev1.EventTypeWarning, "InvalidRollbackTimeout", "Invalid rollback timeout: %s", err)
+			timeout = h.options.RollbackTimeout
+		}
+	}
+
+	reason := ""
+	if progressDeadlineExceeded(object) {
+		reason = "progress deadline exceeded"
+	} else if timeout > 0 && !now.Before(started.Add(timeout)) {
+		reason = fmt.Sprintf("rollout not completed within %s", timeout)
+	} else if timeout > 0 {
+		return reconcile.Result{RequeueAfter: started.Add(timeout).Sub(now)}, nil
+	} else {
+		return reconcile.Result{}, nil
+	}
+
+	if hash != deployedHash {
+		// configuration changed again since the failed rollout was triggered; do not override this change
+		log.Info("rollout failed, but configuration changed in the meantime; skipping rollback", "reason", reason)
+		return reconcile.Result{}, nil
+	}
+
+	log.Info("rollout failed; restoring configuration snapshot", "reason", reason, "hash", snapshot.Annotations[reloader.AnnotationConfigHash])
+	restored, err := h.restoreSnapshot(ctx, object.GetNamespace(), snapshot)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(restored) > 0 {
+		h.recorder.Eventf(object, corev1.EventTypeWarning, "ConfigurationRolledBack", "Rollout of configuration failed (%s); restored previous content of %s", reason, strings.Join(restored, ", "))
+	} else {
+		h.recorder.Eventf(object, corev1.EventTypeWarning, "ConfigurationRollbackSkipped", "Rollout of configuration failed (%s); referenced configuration already matches the snapshot", reason)
+	}
+	return reconcile.Result{}, nil
+}
+
+// collect the current content of the objects referenced by the given workload, in the format of the snapshot data
+// (the serialization is deterministic, so the result can be compared with the data of an existing snapshot)
+func (h *rollbackHandler) snapshotData(ctx context.Context, object ctrlclient.Object, includeSecrets bool) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+	for _, name := range reloader.SplitNames(object.GetAnnotations()[reloader.AnnotationConfigMaps]) {
+		configMap := &corev1.ConfigMap{}
+		if err := h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: object.GetNamespace(), Name: name}, configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		entry, err := json.Marshal(snapshotEntry{Data: configMap.Data, BinaryData: configMap.BinaryData})
+		if err != nil {
+			return nil, err
+		}
+		data["configmap."+name] = entry
+	}
+	if includeSecrets {
+		for _, name := range reloader.SplitNames(object.GetAnnotations()[reloader.AnnotationSecrets]) {
+			secret := &corev1.Secret{}
+			if err := h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: object.GetNamespace(), Name: name}, secret); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			entry, err := json.Marshal(snapshotEntry{BinaryData: secret.Data})
+			if err != nil {
+				return nil, err
+			}
+			data["secret."+name] = entry
+		}
+	}
+	return data, nil
+}
+
+func (h *rollbackHandler) takeSnapshot(ctx context.Context, object ctrlclient.Object, kind string, data map[string][]byte, hash string) error {
+	snapshot := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: object.GetNamespace(),
+			Name:      rollbackSecretName(kind, object.GetName()),
+		},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, h.client, snapshot, func() error {
+		snapshot.Annotations = map[string]string{reloader.AnnotationConfigHash: hash}
+		snapshot.Data = data
+		return controllerutil.SetOwnerReference(object, snapshot, h.client.Scheme())
+	})
+	return err
+}
+
+// restore the referenced objects from the snapshot; returns a description of the actually changed objects
+func (h *rollbackHandler) restoreSnapshot(ctx context.Context, namespace string, snapshot *corev1.Secret) ([]string, error) {
+	var restored []string
+	for key, value := range snapshot.Data {
+		entry := snapshotEntry{}
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return nil, err
+		}
+		kind, name, _ := strings.Cut(key, ".")
+		var object ctrlclient.Object
+		var changed bool
+		switch kind {
+		case "configmap":
+			configMap := &corev1.ConfigMap{}
+			if err := h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: name}, configMap); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			changed = !maps.Equal(configMap.Data, entry.Data) || !maps.EqualFunc(configMap.BinaryData, entry.BinaryData, bytes.Equal)
+			configMap.Data = entry.Data
+			configMap.BinaryData = entry.BinaryData
+			object = configMap
+			kind = "ConfigMap"
+		case "secret":
+			secret := &corev1.Secret{}
+			if err := h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			changed = !maps.EqualFunc(secret.Data, entry.BinaryData, bytes.Equal)
+			secret.Data = entry.BinaryData
+			object = secret
+			kind = "Secret"
+		default:
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if err := h.client.Update(ctx, object); err != nil {
+			return nil, err
+		}
+		restored = append(restored, fmt.Sprintf("%s %s/%s", kind, namespace, name))
+	}
+	return restored, nil
+}
+
+func progressDeadlineExceeded(object ctrlclient.Object) bool {
+	deployment, ok := object.(*appsv1.Deployment)
+	if !ok {
+		return false
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// return the name of the secret holding the rollback snapshot of the specified workload (not to be confused with the
+// immutable configuration snapshots created by the webhook)
+func rollbackSecretName(kind string, name string) string {
+	s := "pod-reloader-rollback-" + strings.ToLower(kind) + "-" + name
+	if len(s) > 253 {
+		sum := sha256.Sum256([]byte(s))
+		s = s[:253-17] + "-" + hex.EncodeToString(sum[:8])
+	}
+	return s
+}
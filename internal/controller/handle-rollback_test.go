@@ -0,0 +1,216 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test configuration rollback", func() {
+	var configMap *corev1.ConfigMap
+	var secret *corev1.Secret
+	var deployment *appsv1.Deployment
+	var h *rollbackHandler
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "good"},
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "secret"},
+			Data:       map[string][]byte{"key": []byte("good")},
+		}
+		deployment = buildDeployment("test", "test", map[string]string{
+			reloader.AnnotationConfigMaps: "config",
+			reloader.AnnotationSecrets:    "secret",
+			reloader.AnnotationRollback:   rollbackConfigMaps,
+		})
+	})
+
+	newRollbackHandler := func(options Options) *rollbackHandler {
+		return &rollbackHandler{
+			client:    newTestClient(configMap, secret, deployment),
+			recorder:  record.NewFakeRecorder(100),
+			options:   options,
+			newObject: func() ctrlclient.Object { return &appsv1.Deployment{} },
+		}
+	}
+
+	reconcileRollback := func() reconcile.Result {
+		result, err := h.Reconcile(ctx, reconcile.Request{NamespacedName: ctrlclient.ObjectKeyFromObject(deployment)})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return result
+	}
+
+	// emulate a reload of the deployment with the current configuration, and the outcome of the triggered rollout
+	rollout := func(complete bool, deadlineExceeded bool) {
+		ExpectWithOffset(1, h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		hash, err := reloader.GenerateHashForObject(ctx, h.client, deployment)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		if deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash] != hash {
+			deployment.Spec.Template.Annotations = map[string]string{reloader.AnnotationConfigHash: hash}
+			deployment.Generation++
+			ExpectWithOffset(1, h.client.Update(ctx, deployment)).To(Succeed())
+		}
+		deployment.Status = appsv1.DeploymentStatus{ObservedGeneration: deployment.Generation, Replicas: 1}
+		if complete {
+			deployment.Status.UpdatedReplicas = 1
+			deployment.Status.AvailableReplicas = 1
+		}
+		if deadlineExceeded {
+			deployment.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"}}
+		}
+		ExpectWithOffset(1, h.client.Status().Update(ctx, deployment)).To(Succeed())
+	}
+
+	updateConfigMap := func(value string) {
+		configMap.Data["key"] = value
+		ExpectWithOffset(1, h.client.Update(ctx, configMap)).To(Succeed())
+	}
+
+	updateSecret := func(value string) {
+		secret.Data["key"] = []byte(value)
+		ExpectWithOffset(1, h.client.Update(ctx, secret)).To(Succeed())
+	}
+
+	getConfigMapValue := func() string {
+		ExpectWithOffset(1, h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(configMap), configMap)).To(Succeed())
+		return configMap.Data["key"]
+	}
+
+	getSnapshot := func() *corev1.Secret {
+		snapshot := &corev1.Secret{}
+		err := h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: "test", Name: rollbackSecretName("Deployment", "test")}, snapshot)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return snapshot
+	}
+
+	It("should take a snapshot after successful rollouts, and restore it after a failed rollout", func() {
+		h = newRollbackHandler(Options{})
+
+		rollout(true, false)
+		reconcileRollback()
+		snapshot := getSnapshot()
+		Expect(snapshot).NotTo(BeNil())
+		Expect(snapshot.OwnerReferences).To(HaveLen(1))
+		Expect(snapshot.Data).To(HaveKey("configmap.config"))
+		Expect(snapshot.Data).NotTo(HaveKey("secret.secret"))
+
+		updateConfigMap("bad")
+		rollout(false, true)
+		reconcileRollback()
+		Expect(getConfigMapValue()).To(Equal("good"))
+		Expect(recordedEvents(h.recorder)).To(ContainElement(ContainSubstring("ConfigurationRolledBack")))
+
+		// the restore triggers another rollout (with a new configuration hash, although the content is the same as in the snapshot)
+		reconcileRollback()
+		rollout(true, false)
+		reconcileRollback()
+		Expect(getConfigMapValue()).To(Equal("good"))
+		Expect(getSnapshot().Data).To(Equal(snapshot.Data))
+
+		// the restored content does not trigger further rollbacks, even if the subsequent rollout fails for other reasons
+		rollout(false, true)
+		reconcileRollback()
+		Expect(recordedEvents(h.recorder)).To(BeEmpty())
+	})
+
+	It("should not restore the snapshot if a rollout fails for other reasons than a configuration change", func() {
+		h = newRollbackHandler(Options{})
+
+		rollout(true, false)
+		reconcileRollback()
+		snapshot := getSnapshot()
+
+		// for example, a rollout caused by an image change
+		deployment.Generation++
+		Expect(h.client.Update(ctx, deployment)).To(Succeed())
+		rollout(false, true)
+		reconcileRollback()
+		Expect(recordedEvents(h.recorder)).To(BeEmpty())
+		Expect(getSnapshot().Data).To(Equal(snapshot.Data))
+	})
+
+	It("should restore the snapshot if a rollout does not complete within the rollback timeout", func() {
+		deployment.Annotations[reloader.AnnotationRollbackTimeout] = "1h"
+		h = newRollbackHandler(Options{})
+
+		rollout(true, false)
+		reconcileRollback()
+
+		updateConfigMap("bad")
+		rollout(false, false)
+		result := reconcileRollback()
+		Expect(result.RequeueAfter).To(BeNumerically(">", 59*time.Minute))
+		Expect(getConfigMapValue()).To(Equal("bad"))
+
+		// emulate the expiry of the timeout
+		snapshot := getSnapshot()
+		snapshot.Annotations[annotationRolloutStarted] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		Expect(h.client.Update(ctx, snapshot)).To(Succeed())
+		reconcileRollback()
+		Expect(getConfigMapValue()).To(Equal("good"))
+	})
+
+	It("should not restore the snapshot if the configuration changed again after the failed rollout", func() {
+		h = newRollbackHandler(Options{})
+
+		rollout(true, false)
+		reconcileRollback()
+
+		updateConfigMap("bad")
+		rollout(false, true)
+		updateConfigMap("fixed")
+		reconcileRollback()
+		Expect(getConfigMapValue()).To(Equal("fixed"))
+	})
+
+	It("should include secrets if requested", func() {
+		deployment.Annotations[reloader.AnnotationRollback] = rollbackAll
+		h = newRollbackHandler(Options{})
+
+		rollout(true, false)
+		reconcileRollback()
+		Expect(getSnapshot().Data).To(HaveKey("secret.secret"))
+
+		updateSecret("bad")
+		rollout(false, true)
+		reconcileRollback()
+		Expect(h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(secret), secret)).To(Succeed())
+		Expect(string(secret.Data["key"])).To(Equal("good"))
+	})
+
+	It("should delete the snapshot if rollbacks are disabled", func() {
+		h = newRollbackHandler(Options{})
+
+		rollout(true, false)
+		reconcileRollback()
+		Expect(getSnapshot()).NotTo(BeNil())
+
+		Expect(h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		delete(deployment.Annotations, reloader.AnnotationRollback)
+		Expect(h.client.Update(ctx, deployment)).To(Succeed())
+		reconcileRollback()
+		Expect(getSnapshot()).To(BeNil())
+	})
+})
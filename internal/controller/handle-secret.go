@@ -9,7 +9,9 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -26,30 +28,38 @@ type secretHandler struct {
 
 var _ reconcile.Reconciler = &secretHandler{}
 
-func newSecretHandler(mgr ctrl.Manager) *secretHandler {
-	return &secretHandler{
-		genericHandler{
-			client:     mgr.GetClient(),
-			recorder:   mgr.GetEventRecorderFor(controllerName),
-			annotation: reloader.AnnotationSecrets,
-		},
-	}
+func newSecretHandler(h genericHandler) *secretHandler {
+	h.annotation = reloader.AnnotationSecrets
+	return &secretHandler{h}
 }
 
-func setupSecretHandler(mgr ctrl.Manager) error {
-	c, err := controller.New(secretHandlerName, mgr, controller.Options{Reconciler: newSecretHandler(mgr), MaxConcurrentReconciles: 5})
+func setupSecretHandler(mgr ctrl.Manager, h genericHandler) error {
+	// if sharding is enabled, the handler runs on all replicas, and each replica handles the namespaces assigned to it
+	c, err := controller.New(secretHandlerName, mgr, controller.Options{Reconciler: scoped(mgr, h.options, newSecretHandler(h)), MaxConcurrentReconciles: 5, NeedLeaderElection: ptr.To(h.sharder == nil), NewQueue: h.options.NewQueue})
 	if err != nil {
 		return err
 	}
+	if h.sharder != nil {
+		if err := c.Watch(h.sharder.resyncSource(func() ctrlclient.ObjectList { return &corev1.SecretList{} }, &handler.EnqueueRequestForObject{})); err != nil {
+			return err
+		}
+	}
 	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.Secret{}, &handler.TypedEnqueueRequestForObject[*corev1.Secret]{})); err != nil {
 		return err
 	}
+	if h.options.HashFieldManager != "" {
+		// the webhook does not update the hash if workloads change, so the controller must take care of that
+		if err := watchReferencingWorkloads(mgr, c, reloader.AnnotationSecrets); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (h *secretHandler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
-	if err := h.handle(ctx, "Secret", request.Namespace, request.Name); err != nil {
+	requeueAfter, err := h.handle(ctx, "Secret", request.Namespace, request.Name)
+	if err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, nil
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
 }
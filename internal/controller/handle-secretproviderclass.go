@@ -0,0 +1,80 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const secretProviderClassHandlerName = "secretproviderclass-handler"
+
+type secretProviderClassHandler struct {
+	genericHandler
+}
+
+var _ reconcile.Reconciler = &secretProviderClassHandler{}
+
+func newSecretProviderClassHandler(h genericHandler) *secretProviderClassHandler {
+	h.annotation = reloader.AnnotationSecretProviderClasses
+	return &secretProviderClassHandler{h}
+}
+
+// the secrets store csi driver reports rotations through SecretProviderClassPodStatus objects (one per pod and secret provider class);
+// these are watched as unstructured (to avoid a dependency on the driver's api), and mapped to the secret provider class they belong to
+func setupSecretProviderClassHandler(mgr ctrl.Manager, h genericHandler) error {
+	c, err := controller.New(secretProviderClassHandlerName, mgr, controller.Options{Reconciler: scoped(mgr, h.options, newSecretProviderClassHandler(h)), MaxConcurrentReconciles: 5, NeedLeaderElection: ptr.To(h.sharder == nil), NewQueue: h.options.NewQueue})
+	if err != nil {
+		return err
+	}
+	toSecretProviderClass := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object ctrlclient.Object) []reconcile.Request {
+		status, ok := object.(*unstructured.Unstructured)
+		if !ok || reloader.SecretProviderClassOf(status) == "" {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: status.GetNamespace(), Name: reloader.SecretProviderClassOf(status)}}}
+	})
+	status := &unstructured.Unstructured{}
+	status.SetGroupVersionKind(reloader.SecretProviderClassPodStatusGroupVersionKind)
+	if err := c.Watch(source.Kind(mgr.GetCache(), ctrlclient.Object(status), toSecretProviderClass)); err != nil {
+		return err
+	}
+	if h.sharder != nil {
+		if err := c.Watch(h.sharder.resyncSource(func() ctrlclient.ObjectList {
+			statusList := &unstructured.UnstructuredList{}
+			statusList.SetGroupVersionKind(reloader.SecretProviderClassPodStatusGroupVersionKind.GroupVersion().WithKind(reloader.SecretProviderClassPodStatusGroupVersionKind.Kind + "List"))
+			return statusList
+		}, toSecretProviderClass)); err != nil {
+			return err
+		}
+	}
+	if h.options.HashFieldManager != "" {
+		// the webhook does not update the hash if workloads change, so the controller must take care of that
+		if err := watchReferencingWorkloads(mgr, c, reloader.AnnotationSecretProviderClasses); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *secretProviderClassHandler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	requeueAfter, err := h.handle(ctx, "SecretProviderClass", request.Namespace, request.Name)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
@@ -0,0 +1,78 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const serviceAccountHandlerName = "serviceaccount-handler"
+
+type serviceAccountHandler struct {
+	genericHandler
+}
+
+var _ reconcile.Reconciler = &serviceAccountHandler{}
+
+// service accounts are not referenced through an annotation of their own; instead, workloads annotated with
+// pod-reloader.cs.sap.com/include-implicit-secrets implicitly reference the service account they are running with
+func newServiceAccountHandler(h genericHandler) *serviceAccountHandler {
+	h.annotation = reloader.AnnotationIncludeImplicitSecrets
+	return &serviceAccountHandler{h}
+}
+
+func setupServiceAccountHandler(mgr ctrl.Manager, h genericHandler) error {
+	c, err := controller.New(serviceAccountHandlerName, mgr, controller.Options{Reconciler: scoped(mgr, h.options, newServiceAccountHandler(h)), MaxConcurrentReconciles: 5, NeedLeaderElection: ptr.To(h.sharder == nil), NewQueue: h.options.NewQueue})
+	if err != nil {
+		return err
+	}
+	if h.sharder != nil {
+		if err := c.Watch(h.sharder.resyncSource(func() ctrlclient.ObjectList { return &corev1.ServiceAccountList{} }, &handler.EnqueueRequestForObject{})); err != nil {
+			return err
+		}
+	}
+	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.ServiceAccount{}, &handler.TypedEnqueueRequestForObject[*corev1.ServiceAccount]{})); err != nil {
+		return err
+	}
+	if h.options.HashFieldManager != "" {
+		// the webhook does not update the hash if workloads change, so the controller must take care of that
+		toServiceAccount := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object ctrlclient.Object) []reconcile.Request {
+			podSpec := reloader.PodSpec(object)
+			if !reloader.IncludesImplicitSecrets(object.GetAnnotations()) || podSpec == nil {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: object.GetNamespace(), Name: reloader.ServiceAccountName(podSpec)}}}
+		})
+		// add additional workload types here
+		for _, object := range []ctrlclient.Object{&appsv1.Deployment{}, &appsv1.StatefulSet{}, &appsv1.DaemonSet{}} {
+			if err := c.Watch(source.Kind(mgr.GetCache(), object, toServiceAccount)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h *serviceAccountHandler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	requeueAfter, err := h.handle(ctx, "ServiceAccount", request.Namespace, request.Name)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
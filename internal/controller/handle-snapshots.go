@@ -0,0 +1,226 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const snapshotHandlerName = "snapshot-handler"
+
+const (
+	// minimum age of snapshots before they are garbage collected; this protects snapshots which were created
+	// by the webhook for requests that are not yet persisted
+	snapshotMinAge = 10 * time.Minute
+	// interval in which namespaces containing snapshots are checked for unreferenced snapshots
+	snapshotCollectInterval = 10 * time.Minute
+)
+
+// snapshotHandler garbage collects immutable config map and secret snapshots (created by the webhook)
+// which are no longer referenced by any workload, replica set or controller revision; in addition, it adds owner references
+// to snapshots referenced by workloads, since the webhook cannot do that upon creation of a workload (where its uid is not yet known);
+// reconcile requests are per namespace (with empty name)
+type snapshotHandler struct {
+	client ctrlclient.Client
+	// uncached reader, used to avoid caching all replica sets and controller revisions of the cluster
+	reader ctrlclient.Reader
+}
+
+var _ reconcile.Reconciler = &snapshotHandler{}
+
+func setupSnapshotHandler(mgr ctrl.Manager, options Options) error {
+	h := &snapshotHandler{
+		client: mgr.GetClient(),
+		reader: mgr.GetAPIReader(),
+	}
+	c, err := controller.New(snapshotHandlerName, mgr, controller.Options{Reconciler: scoped(mgr, options, h), MaxConcurrentReconciles: 1})
+	if err != nil {
+		return err
+	}
+	isSnapshot := predicate.NewPredicateFuncs(func(object ctrlclient.Object) bool {
+		return object.GetLabels()[reloader.LabelSnapshot] == "true"
+	})
+	toNamespace := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object ctrlclient.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: ctrlclient.ObjectKey{Namespace: object.GetNamespace()}}}
+	})
+	if err := c.Watch(source.Kind(mgr.GetCache(), ctrlclient.Object(&corev1.ConfigMap{}), toNamespace, isSnapshot)); err != nil {
+		return err
+	}
+	if err := c.Watch(source.Kind(mgr.GetCache(), ctrlclient.Object(&corev1.Secret{}), toNamespace, isSnapshot)); err != nil {
+		return err
+	}
+	// newly created workloads are not yet owners of the snapshots they reference
+	isCreatedWithImmutableConfig := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.GetAnnotations()[reloader.AnnotationImmutableConfig] == "true"
+		},
+		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+	// add additional workload types here
+	for _, object := range []ctrlclient.Object{&appsv1.Deployment{}, &appsv1.StatefulSet{}, &appsv1.DaemonSet{}} {
+		if err := c.Watch(source.Kind(mgr.GetCache(), object, toNamespace, isCreatedWithImmutableConfig)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *snapshotHandler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	namespace := request.Namespace
+
+	configMapList := &corev1.ConfigMapList{}
+	if err := h.client.List(ctx, configMapList, ctrlclient.InNamespace(namespace), ctrlclient.MatchingLabels{reloader.LabelSnapshot: "true"}); err != nil {
+		return reconcile.Result{}, err
+	}
+	secretList := &corev1.SecretList{}
+	if err := h.client.List(ctx, secretList, ctrlclient.InNamespace(namespace), ctrlclient.MatchingLabels{reloader.LabelSnapshot: "true"}); err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(configMapList.Items) == 0 && len(secretList.Items) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	var workloads []ctrlclient.Object
+	var podTemplates []*corev1.PodTemplateSpec
+
+	// add additional workload types here
+	deploymentList := &appsv1.DeploymentList{}
+	if err := h.client.List(ctx, deploymentList, ctrlclient.InNamespace(namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range deploymentList.Items {
+		workloads = append(workloads, &deploymentList.Items[i])
+	}
+	statefulSetList := &appsv1.StatefulSetList{}
+	if err := h.client.List(ctx, statefulSetList, ctrlclient.InNamespace(namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range statefulSetList.Items {
+		workloads = append(workloads, &statefulSetList.Items[i])
+	}
+	daemonSetList := &appsv1.DaemonSetList{}
+	if err := h.client.List(ctx, daemonSetList, ctrlclient.InNamespace(namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range daemonSetList.Items {
+		workloads = append(workloads, &daemonSetList.Items[i])
+	}
+
+	// workloads referencing a snapshot, by kind and name of the snapshot
+	owners := map[string][]ctrlclient.Object{}
+	for _, workload := range workloads {
+		reloader.VisitReferences(&reloader.PodTemplate(workload).Spec, func(kind string, name *string) {
+			owners[kind+"/"+*name] = append(owners[kind+"/"+*name], workload)
+		})
+	}
+	replicaSetList := &appsv1.ReplicaSetList{}
+	if err := h.reader.List(ctx, replicaSetList, ctrlclient.InNamespace(namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range replicaSetList.Items {
+		podTemplates = append(podTemplates, &replicaSetList.Items[i].Spec.Template)
+	}
+	controllerRevisionList := &appsv1.ControllerRevisionList{}
+	if err := h.reader.List(ctx, controllerRevisionList, ctrlclient.InNamespace(namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for _, controllerRevision := range controllerRevisionList.Items {
+		// revisions of stateful sets and daemon sets contain a patch of the form {"spec":{"template":{...}}}
+		revision := struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}{}
+		if len(controllerRevision.Data.Raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(controllerRevision.Data.Raw, &revision); err != nil {
+			log.V(1).Info("skipping undecodable controller revision", "name", controllerRevision.Name, "error", err.Error())
+			continue
+		}
+		podTemplates = append(podTemplates, &revision.Spec.Template)
+	}
+
+	referenced := map[string]bool{}
+	for key := range owners {
+		referenced[key] = true
+	}
+	for _, podTemplate := range podTemplates {
+		reloader.VisitReferences(&podTemplate.Spec, func(kind string, name *string) {
+			referenced[kind+"/"+*name] = true
+		})
+	}
+
+	now := time.Now()
+	requeueAfter := snapshotCollectInterval
+	var snapshots []ctrlclient.Object
+	for i := range configMapList.Items {
+		snapshots = append(snapshots, &configMapList.Items[i])
+	}
+	for i := range secretList.Items {
+		snapshots = append(snapshots, &secretList.Items[i])
+	}
+	for _, snapshot := range snapshots {
+		kind := reloader.KindConfigMap
+		if _, ok := snapshot.(*corev1.Secret); ok {
+			kind = reloader.KindSecret
+		}
+		if referenced[kind+"/"+snapshot.GetName()] {
+			if err := h.ensureOwners(ctx, snapshot, owners[kind+"/"+snapshot.GetName()]); err != nil {
+				return reconcile.Result{}, err
+			}
+			continue
+		}
+		if age := now.Sub(snapshot.GetCreationTimestamp().Time); age < snapshotMinAge {
+			requeueAfter = minDuration(requeueAfter, snapshotMinAge-age)
+			continue
+		}
+		log.Info("deleting unreferenced snapshot", "kind", kind, "name", snapshot.GetName())
+		if err := h.client.Delete(ctx, snapshot); ctrlclient.IgnoreNotFound(err) != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// add the given workloads as (non-controlling) owners to the given snapshot, if not already present
+func (h *snapshotHandler) ensureOwners(ctx context.Context, snapshot ctrlclient.Object, owners []ctrlclient.Object) error {
+	ownerReferences := slices.Clone(snapshot.GetOwnerReferences())
+	for _, owner := range owners {
+		if err := controllerutil.SetOwnerReference(owner, snapshot, h.client.Scheme()); err != nil {
+			return err
+		}
+	}
+	if equality.Semantic.DeepEqual(snapshot.GetOwnerReferences(), ownerReferences) {
+		return nil
+	}
+	ctrl.LoggerFrom(ctx).Info("adding owner references to snapshot", "name", snapshot.GetName())
+	return h.client.Update(ctx, snapshot)
+}
@@ -0,0 +1,112 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test snapshot handling", func() {
+	var deployment *appsv1.Deployment
+	var h *snapshotHandler
+
+	buildSnapshot := func(name string, age time.Duration) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "test",
+				Name:              name,
+				Labels:            map[string]string{reloader.LabelSnapshot: "true"},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age).Truncate(time.Second)),
+			},
+			Immutable: ptr.To(true),
+		}
+	}
+
+	BeforeEach(func() {
+		deployment = buildDeployment("test", "test", map[string]string{reloader.AnnotationImmutableConfig: "true"})
+		deployment.Spec.Template.Spec.Volumes = []corev1.Volume{{
+			Name:         "config",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config-0123456789"}}},
+		}}
+	})
+
+	newSnapshotHandler := func(objects ...ctrlclient.Object) *snapshotHandler {
+		cli := newTestClient(objects...)
+		return &snapshotHandler{client: cli, reader: cli}
+	}
+
+	reconcileSnapshots := func() reconcile.Result {
+		result, err := h.Reconcile(ctx, reconcile.Request{NamespacedName: ctrlclient.ObjectKey{Namespace: "test"}})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return result
+	}
+
+	getSnapshot := func(name string) *corev1.ConfigMap {
+		snapshot := &corev1.ConfigMap{}
+		err := h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: "test", Name: name}, snapshot)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return snapshot
+	}
+
+	It("should add owner references to snapshots referenced by workloads", func() {
+		h = newSnapshotHandler(deployment, buildSnapshot("config-0123456789", time.Hour))
+		reconcileSnapshots()
+
+		snapshot := getSnapshot("config-0123456789")
+		Expect(snapshot).NotTo(BeNil())
+		Expect(snapshot.OwnerReferences).To(HaveLen(1))
+		Expect(snapshot.OwnerReferences[0].Kind).To(Equal("Deployment"))
+		Expect(snapshot.OwnerReferences[0].UID).To(Equal(deployment.UID))
+		resourceVersion := snapshot.ResourceVersion
+
+		// no further updates once the owner reference exists
+		reconcileSnapshots()
+		Expect(getSnapshot("config-0123456789").ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("should delete unreferenced snapshots after the minimum age", func() {
+		h = newSnapshotHandler(deployment,
+			buildSnapshot("config-0123456789", time.Hour),
+			buildSnapshot("config-1111111111", time.Hour),
+			buildSnapshot("config-2222222222", time.Minute),
+		)
+		result := reconcileSnapshots()
+		Expect(result.RequeueAfter).To(BeNumerically("<=", snapshotMinAge-time.Minute+time.Second))
+
+		Expect(getSnapshot("config-0123456789")).NotTo(BeNil())
+		Expect(getSnapshot("config-1111111111")).To(BeNil())
+		Expect(getSnapshot("config-2222222222")).NotTo(BeNil())
+	})
+
+	It("should keep snapshots referenced by replica sets", func() {
+		replicaSet := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test-1"},
+			Spec:       appsv1.ReplicaSetSpec{Template: deployment.Spec.Template},
+		}
+		h = newSnapshotHandler(replicaSet, buildSnapshot("config-0123456789", time.Hour))
+		reconcileSnapshots()
+
+		snapshot := getSnapshot("config-0123456789")
+		Expect(snapshot).NotTo(BeNil())
+		Expect(snapshot.OwnerReferences).To(BeEmpty())
+	})
+})
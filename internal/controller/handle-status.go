@@ -0,0 +1,313 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	podreloaderv1alpha1 "github.com/sap/pod-reloader/api/v1alpha1"
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const statusHandlerName = "status-handler"
+
+// statusHandler maintains a ReloadStatus object per namespace, reporting the reload state of all workloads
+// in that namespace which reference config maps or secrets; reconcile requests are per namespace (with empty name);
+// the Pending condition reflects the deferrals recorded by the reload handlers (which run in the same process, unless sharding is enabled)
+type statusHandler struct {
+	client    ctrlclient.Client
+	deferrals *deferrals
+	sharder   *sharder
+}
+
+var _ reconcile.Reconciler = &statusHandler{}
+
+func setupStatusHandler(mgr ctrl.Manager, gh genericHandler) error {
+	h := &statusHandler{
+		client:    mgr.GetClient(),
+		deferrals: gh.deferrals,
+		sharder:   gh.sharder,
+	}
+	c, err := controller.New(statusHandlerName, mgr, controller.Options{Reconciler: scoped(mgr, gh.options, h)})
+	if err != nil {
+		return err
+	}
+	toNamespace := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object ctrlclient.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: ctrlclient.ObjectKey{Namespace: object.GetNamespace()}}}
+	})
+	// add additional workload types here
+	for _, object := range []ctrlclient.Object{&appsv1.Deployment{}, &appsv1.StatefulSet{}, &appsv1.DaemonSet{}, &corev1.ConfigMap{}, &corev1.Secret{}, &podreloaderv1alpha1.ReloadStatus{}} {
+		if err := c.Watch(source.Kind(mgr.GetCache(), object, toNamespace)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *statusHandler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running reconcile")
+
+	namespace := request.Namespace
+
+	var objects []ctrlclient.Object
+
+	// add additional workload types here
+	deploymentList := &appsv1.DeploymentList{}
+	if err := h.client.List(ctx, deploymentList, ctrlclient.InNamespace(namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range deploymentList.Items {
+		objects = append(objects, &deploymentList.Items[i])
+	}
+	statefulSetList := &appsv1.StatefulSetList{}
+	if err := h.client.List(ctx, statefulSetList, ctrlclient.InNamespace(namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range statefulSetList.Items {
+		objects = append(objects, &statefulSetList.Items[i])
+	}
+	daemonSetList := &appsv1.DaemonSetList{}
+	if err := h.client.List(ctx, daemonSetList, ctrlclient.InNamespace(namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range daemonSetList.Items {
+		objects = append(objects, &daemonSetList.Items[i])
+	}
+
+	reloadStatus := &podreloaderv1alpha1.ReloadStatus{}
+	if err := h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: podreloaderv1alpha1.ReloadStatusName}, reloadStatus); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		reloadStatus = nil
+	}
+	previousWorkloads := make(map[string]*podreloaderv1alpha1.WorkloadStatus)
+	if reloadStatus != nil {
+		for i := range reloadStatus.Status.Workloads {
+			workload := &reloadStatus.Status.Workloads[i]
+			previousWorkloads[workload.Kind+"/"+workload.Name] = workload
+		}
+	}
+
+	now := metav1.Now()
+	var workloads []podreloaderv1alpha1.WorkloadStatus
+	var requeueAfter time.Duration
+	for _, object := range objects {
+		annotations := object.GetAnnotations()
+		if !reloader.HasReferences(annotations) {
+			continue
+		}
+		gvk, err := apiutil.GVKForObject(object, h.client.Scheme())
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		workload, err := h.workloadStatus(ctx, gvk.Kind, object, previousWorkloads[gvk.Kind+"/"+object.GetName()], now)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		workloads = append(workloads, *workload)
+		// deferrals do not cause watch events, so the status of outdated workloads is refreshed regularly
+		if workload.CurrentHash != workload.DesiredHash {
+			requeueAfter = retryInterval
+		}
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Kind != workloads[j].Kind {
+			return workloads[i].Kind < workloads[j].Kind
+		}
+		return workloads[i].Name < workloads[j].Name
+	})
+
+	if len(workloads) == 0 {
+		if reloadStatus != nil {
+			log.Info("deleting reload status")
+			if err := h.client.Delete(ctx, reloadStatus); ctrlclient.IgnoreNotFound(err) != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, nil
+	}
+
+	if reloadStatus == nil {
+		log.Info("creating reload status")
+		reloadStatus = &podreloaderv1alpha1.ReloadStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      podreloaderv1alpha1.ReloadStatusName,
+			},
+		}
+		if err := h.client.Create(ctx, reloadStatus); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if !equality.Semantic.DeepEqual(reloadStatus.Status.Workloads, workloads) {
+		log.V(1).Info("updating reload status")
+		reloadStatus.Status.Workloads = workloads
+		if err := h.client.Status().Update(ctx, reloadStatus); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// compute the status of the given workload; previous is the workload's status as reported before (may be nil)
+func (h *statusHandler) workloadStatus(ctx context.Context, kind string, object ctrlclient.Object, previous *podreloaderv1alpha1.WorkloadStatus, now metav1.Time) (*podreloaderv1alpha1.WorkloadStatus, error) {
+	annotations := object.GetAnnotations()
+	workload := &podreloaderv1alpha1.WorkloadStatus{
+		Kind:       kind,
+		Name:       object.GetName(),
+		ConfigMaps: reloader.SplitNames(annotations[reloader.AnnotationConfigMaps]),
+		Secrets:    reloader.SplitNames(annotations[reloader.AnnotationSecrets]),
+	}
+	if previous != nil {
+		workload.LastReloadTime = previous.LastReloadTime
+		workload.Conditions = previous.Conditions
+	}
+
+	missing, err := h.missingReferences(ctx, object, workload)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		setCondition(workload, podreloaderv1alpha1.ConditionTypeReferenceMissing, metav1.ConditionTrue, "ReferenceNotFound", fmt.Sprintf("Referenced objects not found: %s", strings.Join(missing, ", ")), now)
+	} else {
+		setCondition(workload, podreloaderv1alpha1.ConditionTypeReferenceMissing, metav1.ConditionFalse, "ReferencesFound", "All referenced objects exist", now)
+	}
+
+	strategy, err := reloadStrategy(object)
+	if err != nil {
+		workload.Strategy = annotations[reloader.AnnotationStrategy]
+		setCondition(workload, podreloaderv1alpha1.ConditionTypeUpToDate, metav1.ConditionUnknown, "InvalidStrategy", err.Error(), now)
+		setCondition(workload, podreloaderv1alpha1.ConditionTypePending, metav1.ConditionUnknown, "InvalidStrategy", err.Error(), now)
+		return workload, nil
+	}
+	workload.Strategy = strategy
+	hash, err := reloader.GenerateHashForObject(ctx, h.client, object)
+	if err != nil {
+		return nil, err
+	}
+	workload.DesiredHash = hash
+	workload.CurrentHash = reloader.AppliedHash(object, strategy)
+	if previous != nil && previous.CurrentHash != "" && previous.CurrentHash != workload.CurrentHash {
+		workload.LastReloadTime = &now
+	}
+
+	if workload.CurrentHash == workload.DesiredHash {
+		setCondition(workload, podreloaderv1alpha1.ConditionTypeUpToDate, metav1.ConditionTrue, "HashUpToDate", "Pods use the current configuration", now)
+		setCondition(workload, podreloaderv1alpha1.ConditionTypePending, metav1.ConditionFalse, "NoReloadPending", "No reload pending", now)
+	} else {
+		setCondition(workload, podreloaderv1alpha1.ConditionTypeUpToDate, metav1.ConditionFalse, "HashOutdated", "Pods use an outdated configuration", now)
+		if h.sharder != nil && !h.sharder.owns(object.GetNamespace()) {
+			setCondition(workload, podreloaderv1alpha1.ConditionTypePending, metav1.ConditionUnknown, "HandledByOtherReplica", "Reloads of this namespace are handled by another replica", now)
+		} else if deferral, ok := h.deferrals.get(object); ok {
+			setCondition(workload, podreloaderv1alpha1.ConditionTypePending, metav1.ConditionTrue, deferral.reason, deferral.message, now)
+		} else {
+			setCondition(workload, podreloaderv1alpha1.ConditionTypePending, metav1.ConditionFalse, "ReloadNotDeferred", "Reload not deferred", now)
+		}
+	}
+
+	return workload, nil
+}
+
+// return the objects referenced by the given workload which do not exist, that is the explicitly referenced config maps, secrets,
+// secret provider classes and other resources, and the implicit secrets (if requested by the workload)
+func (h *statusHandler) missingReferences(ctx context.Context, object ctrlclient.Object, workload *podreloaderv1alpha1.WorkloadStatus) ([]string, error) {
+	annotations := object.GetAnnotations()
+	namespace := object.GetNamespace()
+
+	var missing []string
+	check := func(reference ctrlclient.Object, name string, description string) error {
+		if err := h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: name}, reference); err != nil {
+			if !apierrors.IsNotFound(err) && !apimeta.IsNoMatchError(err) {
+				return err
+			}
+			missing = append(missing, description)
+		}
+		return nil
+	}
+
+	for _, name := range workload.ConfigMaps {
+		if err := check(&corev1.ConfigMap{}, name, reloader.KindConfigMap+" "+name); err != nil {
+			return nil, err
+		}
+	}
+	secrets := workload.Secrets
+	if reloader.IncludesImplicitSecrets(annotations) {
+		if podSpec := reloader.PodSpec(object); podSpec != nil {
+			implicitNames, err := reloader.ImplicitSecretNames(ctx, h.client, namespace, podSpec)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range implicitNames {
+				if !slices.Contains(secrets, name) {
+					secrets = append(slices.Clone(secrets), name)
+				}
+			}
+		}
+	}
+	for _, name := range secrets {
+		if err := check(&corev1.Secret{}, name, reloader.KindSecret+" "+name); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range reloader.SplitNames(annotations[reloader.AnnotationSecretProviderClasses]) {
+		secretProviderClass := &unstructured.Unstructured{}
+		secretProviderClass.SetGroupVersionKind(reloader.SecretProviderClassGroupVersionKind)
+		if err := check(secretProviderClass, name, reloader.SecretProviderClassGroupVersionKind.Kind+" "+name); err != nil {
+			return nil, err
+		}
+	}
+	for _, reference := range reloader.SplitNames(annotations[reloader.AnnotationResources]) {
+		gvk, name, err := reloader.ParseResourceReference(reference)
+		if err != nil {
+			// invalid references cannot be resolved
+			missing = append(missing, reference)
+			continue
+		}
+		resource := &unstructured.Unstructured{}
+		resource.SetGroupVersionKind(gvk)
+		// note: the namespace is ignored for cluster-scoped resources
+		if err := check(resource, name, gvk.Kind+" "+name); err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
+
+func setCondition(workload *podreloaderv1alpha1.WorkloadStatus, conditionType string, status metav1.ConditionStatus, reason string, message string, now metav1.Time) {
+	// note: conditions are copied, since they might be shared with the previous status
+	conditions := append([]metav1.Condition(nil), workload.Conditions...)
+	apimeta.SetStatusCondition(&conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: now,
+	})
+	workload.Conditions = conditions
+}
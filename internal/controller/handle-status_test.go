@@ -0,0 +1,184 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	podreloaderv1alpha1 "github.com/sap/pod-reloader/api/v1alpha1"
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test reload status", func() {
+	var configMap *corev1.ConfigMap
+	var deployment *appsv1.Deployment
+	var h *statusHandler
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "value"},
+		}
+		deployment = buildDeployment("test", "test", map[string]string{
+			reloader.AnnotationConfigMaps: "config",
+			reloader.AnnotationSecrets:    "secret",
+		})
+	})
+
+	reconcileStatus := func() *podreloaderv1alpha1.ReloadStatus {
+		_, err := h.Reconcile(ctx, reconcile.Request{NamespacedName: ctrlclient.ObjectKey{Namespace: "test"}})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		reloadStatus := &podreloaderv1alpha1.ReloadStatus{}
+		err = h.client.Get(ctx, ctrlclient.ObjectKey{Namespace: "test", Name: podreloaderv1alpha1.ReloadStatusName}, reloadStatus)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return reloadStatus
+	}
+
+	// set the configuration hash of the deployment's pod template
+	setTemplateHash := func(hash string) {
+		ExpectWithOffset(1, h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		deployment.Spec.Template.Annotations = map[string]string{reloader.AnnotationConfigHash: hash}
+		ExpectWithOffset(1, h.client.Update(ctx, deployment)).To(Succeed())
+	}
+
+	desiredHash := func() string {
+		hash, err := reloader.GenerateHashForObject(ctx, h.client, deployment)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return hash
+	}
+
+	It("should report outdated workloads and missing references", func() {
+		h = &statusHandler{client: newTestClient(configMap, deployment), deferrals: newDeferrals()}
+
+		reloadStatus := reconcileStatus()
+		Expect(reloadStatus).NotTo(BeNil())
+		Expect(reloadStatus.Status.Workloads).To(HaveLen(1))
+		workload := reloadStatus.Status.Workloads[0]
+		Expect(workload.Kind).To(Equal("Deployment"))
+		Expect(workload.Name).To(Equal("test"))
+		Expect(workload.ConfigMaps).To(ConsistOf("config"))
+		Expect(workload.Secrets).To(ConsistOf("secret"))
+		Expect(workload.Strategy).To(Equal(reloader.StrategyRestart))
+		Expect(workload.CurrentHash).To(BeEmpty())
+		Expect(workload.DesiredHash).To(Equal(desiredHash()))
+		Expect(apimeta.IsStatusConditionFalse(workload.Conditions, podreloaderv1alpha1.ConditionTypeUpToDate)).To(BeTrue())
+		Expect(apimeta.IsStatusConditionFalse(workload.Conditions, podreloaderv1alpha1.ConditionTypePending)).To(BeTrue())
+		condition := apimeta.FindStatusCondition(workload.Conditions, podreloaderv1alpha1.ConditionTypeReferenceMissing)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("Secret secret"))
+		Expect(condition.Message).NotTo(ContainSubstring("ConfigMap config"))
+	})
+
+	It("should report reloads deferred by the reload handlers as pending", func() {
+		deployment.Annotations[reloader.AnnotationMaintenanceWindows] = inactiveMaintenanceWindow()
+		gh := newTestHandler(Options{}, configMap, deployment)
+		h = &statusHandler{client: gh.client, deferrals: gh.deferrals}
+		setTemplateHash("outdated")
+
+		_, err := newConfigMapHandler(gh).Reconcile(ctx, reconcile.Request{NamespacedName: ctrlclient.ObjectKeyFromObject(configMap)})
+		Expect(err).NotTo(HaveOccurred())
+		result, err := h.Reconcile(ctx, reconcile.Request{NamespacedName: ctrlclient.ObjectKey{Namespace: "test"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		workload := reconcileStatus().Status.Workloads[0]
+		condition := apimeta.FindStatusCondition(workload.Conditions, podreloaderv1alpha1.ConditionTypePending)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("OutsideMaintenanceWindow"))
+
+		Expect(h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		delete(deployment.Annotations, reloader.AnnotationMaintenanceWindows)
+		Expect(h.client.Update(ctx, deployment)).To(Succeed())
+		_, err = newConfigMapHandler(gh).Reconcile(ctx, reconcile.Request{NamespacedName: ctrlclient.ObjectKeyFromObject(configMap)})
+		Expect(err).NotTo(HaveOccurred())
+		_, ok := gh.deferrals.get(deployment)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should report up-to-date workloads, and record the time of reloads", func() {
+		h = &statusHandler{client: newTestClient(configMap, deployment), deferrals: newDeferrals()}
+		setTemplateHash(desiredHash())
+
+		workload := reconcileStatus().Status.Workloads[0]
+		Expect(workload.CurrentHash).To(Equal(workload.DesiredHash))
+		Expect(workload.LastReloadTime).To(BeNil())
+		Expect(apimeta.IsStatusConditionTrue(workload.Conditions, podreloaderv1alpha1.ConditionTypeUpToDate)).To(BeTrue())
+		Expect(apimeta.IsStatusConditionFalse(workload.Conditions, podreloaderv1alpha1.ConditionTypePending)).To(BeTrue())
+
+		configMap.Data["key"] = "other"
+		Expect(h.client.Update(ctx, configMap)).To(Succeed())
+		setTemplateHash(desiredHash())
+
+		workload = reconcileStatus().Status.Workloads[0]
+		Expect(workload.CurrentHash).To(Equal(workload.DesiredHash))
+		Expect(workload.LastReloadTime).NotTo(BeNil())
+	})
+
+	It("should report workloads with invalid reload strategies", func() {
+		deployment.Annotations[reloader.AnnotationStrategy] = "invalid"
+		h = &statusHandler{client: newTestClient(configMap, deployment), deferrals: newDeferrals()}
+
+		workload := reconcileStatus().Status.Workloads[0]
+		Expect(workload.Strategy).To(Equal("invalid"))
+		Expect(apimeta.FindStatusCondition(workload.Conditions, podreloaderv1alpha1.ConditionTypeUpToDate).Status).To(Equal(metav1.ConditionUnknown))
+		Expect(apimeta.FindStatusCondition(workload.Conditions, podreloaderv1alpha1.ConditionTypePending).Status).To(Equal(metav1.ConditionUnknown))
+	})
+
+	It("should delete the reload status if no workloads are tracked", func() {
+		h = &statusHandler{client: newTestClient(configMap, deployment), deferrals: newDeferrals()}
+		Expect(reconcileStatus()).NotTo(BeNil())
+
+		Expect(h.client.Delete(ctx, deployment)).To(Succeed())
+		Expect(reconcileStatus()).To(BeNil())
+	})
+
+	It("should ignore workloads without references", func() {
+		h = &statusHandler{client: newTestClient(buildDeployment("test", "other", nil)), deferrals: newDeferrals()}
+		Expect(reconcileStatus()).To(BeNil())
+	})
+
+	It("should report missing secret provider classes, resources and implicit secrets", func() {
+		secretProviderClass := &unstructured.Unstructured{}
+		secretProviderClass.SetGroupVersionKind(reloader.SecretProviderClassGroupVersionKind)
+		secretProviderClass.SetNamespace("test")
+		secretProviderClass.SetName("vault")
+		deployment.Annotations[reloader.AnnotationSecretProviderClasses] = "vault,other-vault"
+		deployment.Annotations[reloader.AnnotationResources] = "v1/ConfigMap:config,v1/ConfigMap:other-config"
+		deployment.Annotations[reloader.AnnotationIncludeImplicitSecrets] = "true"
+		deployment.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull-secret"}}
+		h = &statusHandler{client: newTestClient(configMap, deployment, secretProviderClass), deferrals: newDeferrals()}
+
+		condition := apimeta.FindStatusCondition(reconcileStatus().Status.Workloads[0].Conditions, podreloaderv1alpha1.ConditionTypeReferenceMissing)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("Secret secret"))
+		Expect(condition.Message).To(ContainSubstring("Secret pull-secret"))
+		Expect(condition.Message).To(ContainSubstring("SecretProviderClass other-vault"))
+		Expect(condition.Message).NotTo(ContainSubstring("SecretProviderClass vault"))
+		Expect(condition.Message).To(ContainSubstring("ConfigMap other-config"))
+		Expect(condition.Message).NotTo(ContainSubstring("ConfigMap config"))
+	})
+
+	It("should report all references as found if they exist", func() {
+		delete(deployment.Annotations, reloader.AnnotationSecrets)
+		h = &statusHandler{client: newTestClient(configMap, deployment), deferrals: newDeferrals()}
+
+		condition := apimeta.FindStatusCondition(reconcileStatus().Status.Workloads[0].Conditions, podreloaderv1alpha1.ConditionTypeReferenceMissing)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	})
+})
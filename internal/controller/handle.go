@@ -6,26 +6,58 @@ SPDX-License-Identifier: Apache-2.0
 package controller
 
 import (
+	"bytes"
 	"context"
-	"strings"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
 
 	"github.com/sap/pod-reloader/internal/reloader"
 )
 
 type genericHandler struct {
-	client     ctrlclient.Client
+	client ctrlclient.Client
+	// uncached reader, used for objects which should not be cached (such as pods)
+	reader     ctrlclient.Reader
 	recorder   record.EventRecorder
+	options    Options
+	throttle   *throttle
+	stager     *stager
+	deferrals  *deferrals
+	restarts   *containerRestarts
+	sharder    *sharder
+	executor   commandExecutor
+	caller     *endpointCaller
 	annotation string
 }
 
-func (h *genericHandler) handle(ctx context.Context, kind string, namespace string, name string) error {
+// handle triggers a reload of all workloads referencing the given object;
+// a positive duration is returned if some of the reloads were deferred and the request should be requeued
+func (h *genericHandler) handle(ctx context.Context, kind string, namespace string, name string) (time.Duration, error) {
 	log := ctrl.LoggerFrom(ctx)
+
+	if h.sharder != nil && !h.sharder.owns(namespace) {
+		log.V(1).Info("ignoring request for namespace assigned to other replica")
+		return 0, nil
+	}
+
 	log.V(1).Info("running reconcile")
 
 	objects := make([]ctrlclient.Object, 0)
@@ -35,7 +67,7 @@ func (h *genericHandler) handle(ctx context.Context, kind string, namespace stri
 
 	deploymentList := appsv1.DeploymentList{}
 	if err := h.client.List(ctx, &deploymentList, &ctrlclient.ListOptions{Namespace: namespace}); err != nil {
-		return err
+		return 0, err
 	}
 	for i := 0; i < len(deploymentList.Items); i++ {
 		objects = append(objects, &deploymentList.Items[i])
@@ -43,7 +75,7 @@ func (h *genericHandler) handle(ctx context.Context, kind string, namespace stri
 
 	statefulSetList := appsv1.StatefulSetList{}
 	if err := h.client.List(ctx, &statefulSetList, &ctrlclient.ListOptions{Namespace: namespace}); err != nil {
-		return err
+		return 0, err
 	}
 	for i := 0; i < len(statefulSetList.Items); i++ {
 		objects = append(objects, &statefulSetList.Items[i])
@@ -51,28 +83,341 @@ func (h *genericHandler) handle(ctx context.Context, kind string, namespace stri
 
 	daemonSetList := appsv1.DaemonSetList{}
 	if err := h.client.List(ctx, &daemonSetList, &ctrlclient.ListOptions{Namespace: namespace}); err != nil {
-		return err
+		return 0, err
 	}
 	for i := 0; i < len(daemonSetList.Items); i++ {
 		objects = append(objects, &daemonSetList.Items[i])
 	}
 
+	referencingObjects := make([]ctrlclient.Object, 0)
 	for _, object := range objects {
+		names, err := h.references(ctx, object)
+		if err != nil {
+			return 0, err
+		}
+		if slices.Contains(names, name) {
+			referencingObjects = append(referencingObjects, object)
+		}
+	}
+
+	now := time.Now()
+	requeueAfter, err := h.reloadObjects(ctx, kind, namespace, name, referencingObjects, false, now)
+	if err != nil {
+		return 0, err
+	}
+
+	if h.options.EnablePodEviction {
+		delay, err := h.handlePods(ctx, kind, namespace, name, now)
+		if err != nil {
+			return 0, err
+		}
+		requeueAfter = minDuration(requeueAfter, delay)
+	}
+
+	return requeueAfter, nil
+}
+
+// reload those of the given workloads (all referencing the given object) whose configuration hash is outdated, subject to staging, priorities,
+// maintenance windows, rollout status and throttling; if restart is set, workloads are restarted regardless of their reload strategy
+// (and changes the caller made to the workloads are persisted along with the restart); a positive duration is returned if some of the reloads were deferred
+func (h *genericHandler) reloadObjects(ctx context.Context, kind string, namespace string, name string, referencingObjects []ctrlclient.Object, restart bool, now time.Time) (time.Duration, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var requeueAfter time.Duration
+
+	var eligible map[types.UID]bool
+	if h.stager != nil {
+		var delay time.Duration
+		var err error
+		if eligible, delay, err = h.stageReloads(ctx, kind, namespace, name, referencingObjects, now); err != nil {
+			return 0, err
+		}
+		requeueAfter = minDuration(requeueAfter, delay)
+	}
+
+	blocked, err := h.prioritizeReloads(ctx, referencingObjects)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, object := range referencingObjects {
 		annotations := object.GetAnnotations()
-		if annotations[h.annotation] != "" && contains(strings.Split(annotations[h.annotation], ","), name) {
-			log.Info("annotating object", "kind", object.GetObjectKind().GroupVersionKind(), "namespace", object.GetNamespace(), "name", object.GetName())
-			hash, err := reloader.GenerateHashForObject(ctx, h.client, object)
-			if err != nil {
-				return err
+		gvk, err := apiutil.GVKForObject(object, h.client.Scheme())
+		if err != nil {
+			return 0, err
+		}
+		log := log.WithValues("kind", gvk.Kind, "namespace", object.GetNamespace(), "name", object.GetName())
+		ctx := ctrl.LoggerInto(ctx, log)
+		metricLabels := []string{gvk.Kind, object.GetNamespace(), object.GetName()}
+
+		strategy, err := reloadStrategy(object)
+		if err != nil {
+			log.Error(err, "error determining reload strategy; skipping reload")
+			h.recorder.Eventf(object, corev1.EventTypeWarning, "InvalidStrategy", "Reload due to change of referenced %s %s/%s skipped: %s", kind, namespace, name, err)
+			continue
+		}
+		if restart {
+			strategy = reloader.StrategyRestart
+		}
+
+		hash, err := reloader.GenerateHashForObject(ctx, h.client, object)
+		if err != nil {
+			return 0, err
+		}
+		if reloader.AppliedHash(object, strategy) == hash {
+			log.V(1).Info("configuration hash is up to date")
+			if strategy == reloader.StrategyRestart && h.options.HashFieldManager != "" && !ownsHash(object, h.options.HashFieldManager) {
+				// the initial hash is set by the webhook upon creation, so it is owned by the creator of the workload (e.g. a gitops tool);
+				// applying the same hash makes the configured field manager co-own it, without changing the pod template
+				log.Info("applying initial configuration hash to pod template")
+				if _, err := h.applyHash(ctx, gvk, object, hash); err != nil {
+					return 0, err
+				}
+			}
+			pendingReloads.DeleteLabelValues(metricLabels...)
+			h.deferrals.clear(object)
+			continue
+		}
+
+		if blocked[object.GetUID()] {
+			log.V(1).Info("deferring reload until workloads with higher reload priority have completed their rollout")
+			pendingReloads.WithLabelValues(metricLabels...).Set(1)
+			h.deferrals.record(object, "WaitingForPriority", "Reload deferred until workloads with higher reload priority have completed their rollout", now)
+			requeueAfter = minDuration(requeueAfter, retryInterval)
+			continue
+		}
+
+		if eligible != nil && !eligible[object.GetUID()] {
+			log.V(1).Info("deferring reload until previous reload waves have completed")
+			pendingReloads.WithLabelValues(metricLabels...).Set(1)
+			h.deferrals.record(object, "WaitingForWave", "Reload deferred until previous reload waves have completed", now)
+			continue
+		}
+
+		if deferred, delay := h.checkMaintenanceWindows(ctx, object, kind, namespace, name, now); deferred {
+			pendingReloads.WithLabelValues(metricLabels...).Set(1)
+			h.deferrals.record(object, "OutsideMaintenanceWindow", "Reload deferred until the next maintenance window", now)
+			requeueAfter = minDuration(requeueAfter, delay)
+			continue
+		}
+
+		waitForRollout := h.options.WaitForRollout
+		if s := annotations[reloader.AnnotationWaitForRollout]; s != "" {
+			if waitForRollout, err = strconv.ParseBool(s); err != nil {
+				log.Error(err, "error parsing wait-for-rollout annotation; falling back to default")
+				h.recorder.Eventf(object, corev1.EventTypeWarning, "InvalidWaitForRollout", "Invalid wait-for-rollout annotation: %s", err)
+				waitForRollout = h.options.WaitForRollout
+			}
+		}
+		if strategy == reloader.StrategyRestart && waitForRollout && !rolloutComplete(object) {
+			log.Info("deferring reload until current rollout has completed")
+			pendingReloads.WithLabelValues(metricLabels...).Set(1)
+			h.deferrals.record(object, "WaitingForRollout", "Reload deferred until the current rollout has completed", now)
+			requeueAfter = minDuration(requeueAfter, retryInterval)
+			continue
+		}
+
+		interval, err := h.throttle.reloadInterval(object)
+		if err != nil {
+			log.Error(err, "error parsing minimum reload interval; falling back to default")
+			h.recorder.Eventf(object, corev1.EventTypeWarning, "InvalidMinReloadInterval", "Invalid minimum reload interval: %s", err)
+			interval = h.throttle.minReloadInterval
+		}
+		if delay := h.throttle.reloadDelay(object, now); delay > 0 {
+			log.Info("deferring reload due to minimum reload interval", "delay", delay)
+			pendingReloads.WithLabelValues(metricLabels...).Set(1)
+			h.deferrals.record(object, "MinReloadInterval", fmt.Sprintf("Reload deferred due to minimum reload interval (until %s)", formatTime(now.Add(delay))), now)
+			requeueAfter = minDuration(requeueAfter, delay)
+			continue
+		}
+
+		if strategy != reloader.StrategyRestart {
+			if delay := h.throttle.syncDelay(object, hash, h.options.VolumeSyncDelay, now); delay > 0 {
+				log.Info("deferring in-place reload until mounted volumes are synced", "delay", delay)
+				pendingReloads.WithLabelValues(metricLabels...).Set(1)
+				h.deferrals.record(object, "WaitingForVolumeSync", "In-place reload deferred until mounted volumes are synced", now)
+				requeueAfter = minDuration(requeueAfter, delay)
+				continue
+			}
+			log.Info("reloading in-place", "strategy", strategy)
+			if delay, err := h.reloadInPlace(ctx, object, strategy, now); err == nil && delay > 0 {
+				log.Info("in-place reload in progress", "delay", delay)
+				pendingReloads.WithLabelValues(metricLabels...).Set(1)
+				h.deferrals.record(object, "WaitingForContainerRestarts", "In-place reload waiting for the restarted containers to come up", now)
+				requeueAfter = minDuration(requeueAfter, delay)
+				continue
+			} else if err == nil {
+				if err := h.setAppliedHash(ctx, object, hash); err != nil {
+					return 0, err
+				}
+				h.throttle.recordReload(object, interval, now)
+				pendingReloads.DeleteLabelValues(metricLabels...)
+				h.deferrals.clear(object)
+				h.recorder.Eventf(object, corev1.EventTypeNormal, "ConfigurationChanged", "In-place reload triggered due to change of referenced %s %s/%s", kind, namespace, name)
+				continue
+			} else if strategy != reloader.StrategyHTTP && strategy != reloader.StrategyRestartContainers {
+				h.recorder.Eventf(object, corev1.EventTypeWarning, "ReloadFailed", "In-place reload due to change of referenced %s %s/%s failed: %s", kind, namespace, name, err)
+				return 0, err
+			} else {
+				// fall back to restarting the pods; the injected hash makes the webhook update the pod template
+				// (and the applied hashes, so the in-place reload is not retried after the rollout)
+				log.Error(err, "in-place reload failed; falling back to restart")
+				h.recorder.Eventf(object, corev1.EventTypeWarning, "ReloadFailed", "In-place reload due to change of referenced %s %s/%s failed, falling back to restart: %s", kind, namespace, name, err)
 			}
-			annotations[reloader.AnnotationConfigHash] = hash
-			object.SetAnnotations(annotations)
+		}
+
+		if ok, err := h.throttle.acquireRollout(ctx, object); err != nil {
+			return 0, err
+		} else if !ok {
+			log.Info("deferring reload due to maximum number of concurrent rollouts")
+			pendingReloads.WithLabelValues(metricLabels...).Set(1)
+			h.deferrals.record(object, "MaxConcurrentRollouts", "Reload deferred due to maximum number of concurrent rollouts", now)
+			requeueAfter = minDuration(requeueAfter, retryInterval)
+			continue
+		}
+
+		h.throttle.markRollout(object)
+		if h.options.HashFieldManager != "" {
+			if restart {
+				// forced restarts may come with changes of the workload's annotations (such as the image digest revision), which must be persisted
+				// before the hash is applied, since the webhook validates the hash against the annotations
+				if err := h.client.Update(ctx, object); err != nil {
+					h.throttle.releaseRollout(object)
+					return 0, err
+				}
+			}
+			log.Info("applying configuration hash to pod template")
+			if object, err = h.applyHash(ctx, gvk, object, hash); err != nil {
+				h.throttle.releaseRollout(object)
+				return 0, err
+			}
+		} else {
+			log.Info("annotating object")
+			reloader.InjectHash(object, hash)
 			if err := h.client.Update(ctx, object); err != nil {
-				return err
+				h.throttle.releaseRollout(object)
+				return 0, err
 			}
-			h.recorder.Eventf(object, corev1.EventTypeNormal, "ConfigurationChanged", "Reload triggered due to change of referenced %s %s/%s", kind, namespace, name)
+		}
+		h.throttle.recordReload(object, interval, now)
+		pendingReloads.DeleteLabelValues(metricLabels...)
+		h.deferrals.clear(object)
+		h.recorder.Eventf(object, corev1.EventTypeNormal, "ConfigurationChanged", "Reload triggered due to change of referenced %s %s/%s", kind, namespace, name)
+	}
+
+	return requeueAfter, nil
+}
+
+// set the configuration hash on the pod template of the given workload through server-side apply, using the configured field manager;
+// returns the updated workload (or the unchanged one in case of errors)
+func (h *genericHandler) applyHash(ctx context.Context, gvk schema.GroupVersionKind, object ctrlclient.Object, hash string) (ctrlclient.Object, error) {
+	patch := &unstructured.Unstructured{}
+	patch.SetGroupVersionKind(gvk)
+	patch.SetNamespace(object.GetNamespace())
+	patch.SetName(object.GetName())
+	if err := unstructured.SetNestedField(patch.Object, hash, "spec", "template", "metadata", "annotations", reloader.AnnotationConfigHash); err != nil {
+		return object, err
+	}
+	if generation := object.GetAnnotations()[reloader.AnnotationRolloutGeneration]; generation != "" {
+		// see throttle.markRollout()
+		if err := unstructured.SetNestedField(patch.Object, generation, "metadata", "annotations", reloader.AnnotationRolloutGeneration); err != nil {
+			return object, err
 		}
 	}
+	if err := h.client.Patch(ctx, patch, ctrlclient.Apply, ctrlclient.FieldOwner(h.options.HashFieldManager), ctrlclient.ForceOwnership); err != nil {
+		return object, err
+	}
+	return patch, nil
+}
+
+// check if the configuration hash on the pod template of the given workload is owned by the given field manager (through server-side apply)
+func ownsHash(object ctrlclient.Object, manager string) bool {
+	path := fieldpath.MakePathOrDie("spec", "template", "metadata", "annotations", reloader.AnnotationConfigHash)
+	for _, entry := range object.GetManagedFields() {
+		if entry.Manager != manager || entry.Operation != metav1.ManagedFieldsOperationApply || entry.FieldsV1 == nil {
+			continue
+		}
+		set := &fieldpath.Set{}
+		if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			continue
+		}
+		if set.Has(path) {
+			return true
+		}
+	}
+	return false
+}
 
+// watch workloads referencing objects through the given annotation, and enqueue the referenced objects upon changes
+func watchReferencingWorkloads(mgr ctrl.Manager, c controller.Controller, annotation string) error {
+	toReferences := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object ctrlclient.Object) []reconcile.Request {
+		var requests []reconcile.Request
+		for _, name := range reloader.SplitNames(object.GetAnnotations()[annotation]) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: object.GetNamespace(), Name: name}})
+		}
+		return requests
+	})
+	// add additional workload types here
+	for _, object := range []ctrlclient.Object{&appsv1.Deployment{}, &appsv1.StatefulSet{}, &appsv1.DaemonSet{}} {
+		if err := c.Watch(source.Kind(mgr.GetCache(), object, toReferences)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// return the names of the objects handled by this handler which are referenced by the given workload or pod; besides the names
+// listed in the handler's annotation, this includes implicitly referenced secrets (respectively the service account), if requested
+func (h *genericHandler) references(ctx context.Context, object ctrlclient.Object) ([]string, error) {
+	names := reloader.SplitNames(object.GetAnnotations()[h.annotation])
+	if h.annotation != reloader.AnnotationSecrets && h.annotation != reloader.AnnotationIncludeImplicitSecrets {
+		return names, nil
+	}
+	podSpec := reloader.PodSpec(object)
+	if !h.options.EnableImplicitSecrets || !reloader.IncludesImplicitSecrets(object.GetAnnotations()) || podSpec == nil {
+		if h.annotation == reloader.AnnotationIncludeImplicitSecrets {
+			return nil, nil
+		}
+		return names, nil
+	}
+	if h.annotation == reloader.AnnotationIncludeImplicitSecrets {
+		return []string{reloader.ServiceAccountName(podSpec)}, nil
+	}
+	implicitNames, err := reloader.ImplicitSecretNames(ctx, h.client, object.GetNamespace(), podSpec)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range implicitNames {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// check if a reload of the given object must be deferred due to maintenance windows; if so, the
+// second return value is the time until the next window starts (zero if there is no such window)
+func (h *genericHandler) checkMaintenanceWindows(ctx context.Context, object ctrlclient.Object, kind string, namespace string, name string, now time.Time) (bool, time.Duration) {
+	log := ctrl.LoggerFrom(ctx)
+
+	annotations := object.GetAnnotations()
+	if annotations[reloader.AnnotationMaintenanceWindows] == "" {
+		return false, 0
+	}
+	windows, err := reloader.ParseMaintenanceWindows(annotations[reloader.AnnotationMaintenanceWindows])
+	if err != nil {
+		// do not reload outside of maintenance windows if the windows cannot be determined
+		log.Error(err, "error parsing maintenance windows; skipping reload")
+		h.recorder.Eventf(object, corev1.EventTypeWarning, "InvalidMaintenanceWindows", "Reload due to change of referenced %s %s/%s skipped: %s", kind, namespace, name, err)
+		return true, 0
+	}
+	if active, next := reloader.NextMaintenanceWindow(windows, now); !active {
+		log.Info("deferring reload until next maintenance window", "next", next)
+		h.recorder.Eventf(object, corev1.EventTypeNormal, "ReloadDeferred", "Reload due to change of referenced %s %s/%s deferred until next maintenance window (%s)", kind, namespace, name, formatTime(next))
+		if next.IsZero() {
+			return true, 0
+		}
+		return true, next.Sub(now)
+	}
+	return false, 0
+}
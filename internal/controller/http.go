@@ -0,0 +1,164 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	defaultReloadPath   = "/-/reload"
+	defaultReloadMethod = http.MethodPost
+)
+
+// endpointCaller calls http reload endpoints of pods, either directly through the pod ip, or through the api server's pods/proxy subresource
+type endpointCaller struct {
+	httpClient *http.Client
+	clientset  kubernetes.Interface
+	viaProxy   bool
+	timeout    time.Duration
+	retries    int
+}
+
+type reloadEndpoint struct {
+	scheme string
+	port   string
+	path   string
+	method string
+}
+
+func newEndpointCaller(config *rest.Config, viaProxy bool, timeout time.Duration, retries int, caFile string, insecureSkipVerify bool) (*endpointCaller, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := newEndpointTLSConfig(caFile, insecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &endpointCaller{
+		httpClient: &http.Client{Transport: transport},
+		clientset:  clientset,
+		viaProxy:   viaProxy,
+		timeout:    timeout,
+		retries:    retries,
+	}, nil
+}
+
+// build the tls configuration for calling https endpoints directly; server certificates are verified against the system roots,
+// or against the ca certificates in the given file (if any); since pods are called through their ip, certificates must be valid for that ip,
+// unless verification is skipped
+func newEndpointTLSConfig(caFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+	if caFile != "" {
+		caData, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading reload ca file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no certificates found in reload ca file %s", caFile)
+		}
+	}
+	return tlsConfig, nil
+}
+
+// call the endpoint on the given pod, retrying with a linear backoff
+func (c *endpointCaller) call(ctx context.Context, pod *corev1.Pod, endpoint reloadEndpoint) error {
+	port, err := resolvePort(pod, endpoint.port)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+		if c.viaProxy {
+			lastErr = c.callViaProxy(ctx, pod, port, endpoint)
+		} else {
+			lastErr = c.callDirectly(ctx, pod, port, endpoint)
+		}
+		if lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+func (c *endpointCaller) callDirectly(ctx context.Context, pod *corev1.Pod, port int, endpoint reloadEndpoint) error {
+	if pod.Status.PodIP == "" {
+		return fmt.Errorf("pod has no ip address")
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	url := fmt.Sprintf("%s://%s%s", endpoint.scheme, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port)), endpoint.path)
+	req, err := http.NewRequestWithContext(ctx, endpoint.method, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (c *endpointCaller) callViaProxy(ctx context.Context, pod *corev1.Pod, port int, endpoint reloadEndpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.clientset.CoreV1().RESTClient().Verb(endpoint.method).
+		Namespace(pod.Namespace).
+		Resource("pods").
+		Name(fmt.Sprintf("%s:%s:%d", endpoint.scheme, pod.Name, port)).
+		SubResource("proxy").
+		Suffix(endpoint.path).
+		Do(ctx).
+		Error()
+}
+
+// resolve a numeric or named container port
+func resolvePort(pod *corev1.Pod, port string) (int, error) {
+	if n, err := strconv.Atoi(port); err == nil {
+		return n, nil
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.Name == port {
+				return int(containerPort.ContainerPort), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("port %s not found in pod spec", port)
+}
@@ -0,0 +1,70 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Test calling https reload endpoints", func() {
+	var server *httptest.Server
+	var pod *corev1.Pod
+	var endpoint reloadEndpoint
+	var caFile string
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		u, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		pod = buildPod("test", "test-1", nil, true, "app")
+		endpoint = reloadEndpoint{scheme: "https", port: u.Port(), path: defaultReloadPath, method: defaultReloadMethod}
+
+		caFile = filepath.Join(GinkgoT().TempDir(), "ca.crt")
+		Expect(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newCaller := func(caFile string, insecureSkipVerify bool) *endpointCaller {
+		caller, err := newEndpointCaller(&rest.Config{Host: "https://localhost"}, false, time.Second, 0, caFile, insecureSkipVerify)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return caller
+	}
+
+	It("should reject untrusted certificates by default", func() {
+		Expect(newCaller("", false).call(ctx, pod, endpoint)).To(MatchError(ContainSubstring("certificate")))
+	})
+
+	It("should accept certificates signed by the given ca", func() {
+		Expect(newCaller(caFile, false).call(ctx, pod, endpoint)).To(Succeed())
+	})
+
+	It("should accept untrusted certificates if verification is skipped", func() {
+		Expect(newCaller("", true).call(ctx, pod, endpoint)).To(Succeed())
+	})
+
+	It("should fail for invalid ca files", func() {
+		Expect(os.WriteFile(caFile, []byte("invalid"), 0o600)).To(Succeed())
+		_, err := newEndpointCaller(&rest.Config{Host: "https://localhost"}, false, time.Second, 0, caFile, false)
+		Expect(err).To(MatchError(ContainSubstring("no certificates found")))
+	})
+})
@@ -0,0 +1,23 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var pendingReloads = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "pod_reloader_pending_reloads",
+		Help: "Workloads with a configuration change whose reload is currently deferred (1 while pending)",
+	},
+	[]string{"kind", "namespace", "name"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(pendingReloads)
+}
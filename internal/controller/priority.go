@@ -0,0 +1,73 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+// determine which of the given workloads (all referencing the same object) are blocked by workloads with a higher reload priority,
+// that is, by workloads which still have to be reloaded, or whose rollout is still in progress
+func (h *genericHandler) prioritizeReloads(ctx context.Context, objects []ctrlclient.Object) (map[types.UID]bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	priorities := make(map[types.UID]int)
+	prioritized := false
+	for _, object := range objects {
+		priority := 0
+		if s := object.GetAnnotations()[reloader.AnnotationReloadPriority]; s != "" {
+			p, err := strconv.Atoi(s)
+			if err != nil {
+				log.Error(err, "error parsing reload priority; falling back to default", "namespace", object.GetNamespace(), "name", object.GetName())
+				h.recorder.Eventf(object, corev1.EventTypeWarning, "InvalidReloadPriority", "Invalid reload priority: %s", err)
+			} else {
+				priority = p
+			}
+		}
+		priorities[object.GetUID()] = priority
+		if priority != priorities[objects[0].GetUID()] {
+			prioritized = true
+		}
+	}
+	if !prioritized {
+		return nil, nil
+	}
+
+	activePriority := math.MinInt
+	for _, object := range objects {
+		if priorities[object.GetUID()] <= activePriority {
+			continue
+		}
+		strategy, err := reloadStrategy(object)
+		if err != nil {
+			continue
+		}
+		hash, err := reloader.GenerateHashForObject(ctx, h.client, object)
+		if err != nil {
+			return nil, err
+		}
+		if reloader.AppliedHash(object, strategy) != hash || (strategy == reloader.StrategyRestart && !rolloutComplete(object)) {
+			activePriority = priorities[object.GetUID()]
+		}
+	}
+
+	blocked := make(map[types.UID]bool)
+	for _, object := range objects {
+		if priorities[object.GetUID()] < activePriority {
+			blocked[object.GetUID()] = true
+		}
+	}
+	return blocked, nil
+}
@@ -0,0 +1,80 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test reload priorities", func() {
+	var configMap *corev1.ConfigMap
+	var backend *appsv1.Deployment
+	var frontend *appsv1.Deployment
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "value"},
+		}
+		backend = buildDeployment("test", "backend", map[string]string{
+			reloader.AnnotationConfigMaps:     "config",
+			reloader.AnnotationReloadPriority: "10",
+		})
+		frontend = buildDeployment("test", "frontend", map[string]string{reloader.AnnotationConfigMaps: "config"})
+	})
+
+	It("should reload workloads with lower priority only after the rollouts of workloads with higher priority have completed", func() {
+		h := newConfigMapHandler(newTestHandler(Options{}, configMap, backend, frontend))
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(admitInjectedHash(h.genericHandler, backend)).To(BeTrue())
+		Expect(admitInjectedHash(h.genericHandler, frontend)).To(BeFalse())
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(admitInjectedHash(h.genericHandler, frontend)).To(BeFalse())
+
+		completeRollout(h.genericHandler, backend)
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(admitInjectedHash(h.genericHandler, frontend)).To(BeTrue())
+	})
+
+	It("should reload workloads with equal priorities at once", func() {
+		frontend.Annotations[reloader.AnnotationReloadPriority] = "10"
+		h := newConfigMapHandler(newTestHandler(Options{}, configMap, backend, frontend))
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(admitInjectedHash(h.genericHandler, backend)).To(BeTrue())
+		Expect(admitInjectedHash(h.genericHandler, frontend)).To(BeTrue())
+	})
+
+	It("should not be blocked by up-to-date workloads with higher priority", func() {
+		h := newConfigMapHandler(newTestHandler(Options{}, configMap, backend))
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(admitInjectedHash(h.genericHandler, backend)).To(BeTrue())
+		completeRollout(h.genericHandler, backend)
+
+		Expect(h.client.Create(ctx, frontend)).To(Succeed())
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(admitInjectedHash(h.genericHandler, frontend)).To(BeTrue())
+	})
+
+	It("should fall back to the default priority for invalid priorities", func() {
+		backend.Annotations[reloader.AnnotationReloadPriority] = "high"
+		h := newConfigMapHandler(newTestHandler(Options{}, configMap, backend, frontend))
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(admitInjectedHash(h.genericHandler, backend)).To(BeTrue())
+		Expect(admitInjectedHash(h.genericHandler, frontend)).To(BeTrue())
+		Expect(recordedEvents(h.recorder)).To(ContainElement(ContainSubstring("InvalidReloadPriority")))
+	})
+})
@@ -0,0 +1,56 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// check if the most recent rollout of the given workload has completed (in the sense of 'kubectl rollout status')
+func rolloutComplete(object ctrlclient.Object) bool {
+	switch obj := object.(type) {
+	// add additional workload types here
+	case *appsv1.Deployment:
+		replicas := int32(1)
+		if obj.Spec.Replicas != nil {
+			replicas = *obj.Spec.Replicas
+		}
+		return obj.Status.ObservedGeneration >= obj.Generation &&
+			obj.Status.UpdatedReplicas >= replicas &&
+			obj.Status.Replicas <= obj.Status.UpdatedReplicas &&
+			obj.Status.AvailableReplicas >= obj.Status.UpdatedReplicas
+	case *appsv1.StatefulSet:
+		if obj.Status.ObservedGeneration < obj.Generation {
+			return false
+		}
+		if obj.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+			return true
+		}
+		replicas := int32(1)
+		if obj.Spec.Replicas != nil {
+			replicas = *obj.Spec.Replicas
+		}
+		if obj.Status.ReadyReplicas < replicas {
+			return false
+		}
+		if ru := obj.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition > 0 {
+			return obj.Status.UpdatedReplicas >= replicas-*ru.Partition
+		}
+		return obj.Status.UpdateRevision == obj.Status.CurrentRevision
+	case *appsv1.DaemonSet:
+		if obj.Status.ObservedGeneration < obj.Generation {
+			return false
+		}
+		if obj.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+			return true
+		}
+		return obj.Status.UpdatedNumberScheduled >= obj.Status.DesiredNumberScheduled &&
+			obj.Status.NumberAvailable >= obj.Status.DesiredNumberScheduled
+	default:
+		return true
+	}
+}
@@ -0,0 +1,128 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test rollout status", func() {
+	DescribeTable("rolloutComplete",
+		func(object ctrlclient.Object, complete bool) {
+			Expect(rolloutComplete(object)).To(Equal(complete))
+		},
+		Entry("deployment with outdated observed generation",
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+			}, false),
+		Entry("deployment with outdated replicas",
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+			}, false),
+		Entry("deployment with unavailable replicas",
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 0},
+			}, false),
+		Entry("completed deployment",
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+			}, true),
+		Entry("statefulset with outdated revision",
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 1, CurrentRevision: "a", UpdateRevision: "b"},
+			}, false),
+		Entry("completed statefulset",
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 1, CurrentRevision: "b", UpdateRevision: "b"},
+			}, true),
+		Entry("partitioned statefulset",
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: ptr.To[int32](3),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: ptr.To[int32](2)},
+					},
+				},
+				Status: appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "a", UpdateRevision: "b"},
+			}, true),
+		Entry("statefulset with on-delete update strategy",
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.StatefulSetSpec{UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, CurrentRevision: "a", UpdateRevision: "b"},
+			}, true),
+		Entry("daemonset with unavailable pods",
+			&appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberAvailable: 1},
+			}, false),
+		Entry("completed daemonset",
+			&appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberAvailable: 2},
+			}, true),
+	)
+
+	Context("waiting for rollouts", func() {
+		var configMap *corev1.ConfigMap
+		var deployment *appsv1.Deployment
+
+		BeforeEach(func() {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+				Data:       map[string]string{"key": "value"},
+			}
+			deployment = buildDeployment("test", "test", map[string]string{reloader.AnnotationConfigMaps: "config"})
+			// rollout in progress
+			deployment.Status.ObservedGeneration = 0
+		})
+
+		It("should defer reloads until the current rollout has completed", func() {
+			h := newConfigMapHandler(newTestHandler(Options{WaitForRollout: true}, configMap, deployment))
+
+			requeueAfter, err := h.handle(ctx, "ConfigMap", "test", "config")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requeueAfter).To(Equal(retryInterval))
+			Expect(injectedHash(h.genericHandler, deployment)).To(BeEmpty())
+
+			deployment.Status = appsv1.DeploymentStatus{ObservedGeneration: deployment.Generation, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+			Expect(h.client.Status().Update(ctx, deployment)).To(Succeed())
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			Expect(injectedHash(h.genericHandler, deployment)).NotTo(BeEmpty())
+		})
+
+		It("should let the annotation override the default", func() {
+			deployment.Annotations[reloader.AnnotationWaitForRollout] = "false"
+			h := newConfigMapHandler(newTestHandler(Options{WaitForRollout: true}, configMap, deployment))
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			Expect(injectedHash(h.genericHandler, deployment)).NotTo(BeEmpty())
+		})
+
+		It("should not wait for rollouts by default", func() {
+			h := newConfigMapHandler(newTestHandler(Options{}, configMap, deployment))
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			Expect(injectedHash(h.genericHandler, deployment)).NotTo(BeEmpty())
+		})
+	})
+})
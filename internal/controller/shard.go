@@ -0,0 +1,223 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const (
+	// validity of a shard lease; members whose lease was not renewed within this time are considered as gone
+	shardLeaseDuration = 30 * time.Second
+	// interval in which shard leases are renewed (and the membership is refreshed)
+	shardRenewInterval = 10 * time.Second
+	// prefix for the names of shard leases
+	shardLeasePrefix = "pod-reloader-shard-"
+)
+
+// sharder coordinates multiple active replicas through leases, and assigns namespaces to replicas by rendezvous hashing;
+// when the set of members changes, objects in namespaces newly assigned to this replica are resynced
+type sharder struct {
+	client ctrlclient.Client
+	// uncached reader, used for leases (which may live in a namespace not watched by the cache)
+	reader    ctrlclient.Reader
+	namespace string
+	identity  string
+	mutex     sync.RWMutex
+	members   []string
+	targets   []resyncTarget
+}
+
+type resyncTarget struct {
+	newList func() ctrlclient.ObjectList
+	events  chan event.GenericEvent
+}
+
+var _ manager.Runnable = &sharder{}
+var _ manager.LeaderElectionRunnable = &sharder{}
+
+func newSharder(client ctrlclient.Client, reader ctrlclient.Reader, namespace string, identity string) *sharder {
+	return &sharder{
+		client:    client,
+		reader:    reader,
+		namespace: namespace,
+		identity:  identity,
+	}
+}
+
+// return a source emitting the objects (listed through the given function) of namespaces newly assigned to this replica,
+// mapped to requests by the given handler; must be called before the sharder is started
+func (s *sharder) resyncSource(newList func() ctrlclient.ObjectList, eventHandler handler.EventHandler) source.Source {
+	events := make(chan event.GenericEvent)
+	s.targets = append(s.targets, resyncTarget{newList: newList, events: events})
+	return source.Channel(events, eventHandler)
+}
+
+// check whether the given namespace is assigned to this replica; before the membership is known, nothing is assigned
+func (s *sharder) owns(namespace string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return shardOwner(s.members, namespace) == s.identity
+}
+
+// return the member with the highest score for the given namespace (rendezvous hashing)
+func shardOwner(members []string, namespace string) string {
+	var owner string
+	var ownerScore uint64
+	for _, member := range members {
+		h := fnv.New64a()
+		h.Write([]byte(member + "/" + namespace))
+		score := h.Sum64()
+		if owner == "" || score > ownerScore || (score == ownerScore && member < owner) {
+			owner = member
+			ownerScore = score
+		}
+	}
+	return owner
+}
+
+func (s *sharder) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *sharder) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("sharder").WithValues("identity", s.identity)
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	ticker := time.NewTicker(shardRenewInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.refresh(ctx); err != nil {
+			log.Error(err, "error refreshing shard membership")
+		}
+		select {
+		case <-ctx.Done():
+			// release the lease, such that the other members take over immediately
+			lease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: shardLeasePrefix + s.identity}}
+			if err := s.client.Delete(context.Background(), lease); err != nil && !apierrors.IsNotFound(err) {
+				log.Error(err, "error releasing shard lease")
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// renew the own lease, and update the set of members from the leases of all replicas
+func (s *sharder) refresh(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	now := metav1.NewMicroTime(time.Now())
+	if err := s.renewLease(ctx, now); err != nil {
+		return err
+	}
+
+	leaseList := &coordinationv1.LeaseList{}
+	if err := s.reader.List(ctx, leaseList, ctrlclient.InNamespace(s.namespace), ctrlclient.MatchingLabels{reloader.LabelShard: "true"}); err != nil {
+		return err
+	}
+	members := []string{s.identity}
+	for _, lease := range leaseList.Items {
+		if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == s.identity || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		if lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second).Before(now.Time) {
+			continue
+		}
+		members = append(members, *lease.Spec.HolderIdentity)
+	}
+	slices.Sort(members)
+
+	s.mutex.Lock()
+	oldMembers := s.members
+	if slices.Equal(oldMembers, members) {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.members = members
+	s.mutex.Unlock()
+
+	log.Info("shard membership changed", "members", members)
+	if err := s.resync(ctx, oldMembers, members); err != nil {
+		// restore the previous membership, such that the resync is retried upon next refresh
+		s.mutex.Lock()
+		s.members = oldMembers
+		s.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *sharder) renewLease(ctx context.Context, now metav1.MicroTime) error {
+	lease := &coordinationv1.Lease{}
+	if err := s.reader.Get(ctx, ctrlclient.ObjectKey{Namespace: s.namespace, Name: shardLeasePrefix + s.identity}, lease); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      shardLeasePrefix + s.identity,
+				Labels:    map[string]string{reloader.LabelShard: "true"},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &s.identity,
+				LeaseDurationSeconds: &[]int32{int32(shardLeaseDuration / time.Second)}[0],
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		return s.client.Create(ctx, lease)
+	}
+	lease.Spec.HolderIdentity = &s.identity
+	lease.Spec.LeaseDurationSeconds = &[]int32{int32(shardLeaseDuration / time.Second)}[0]
+	lease.Spec.RenewTime = &now
+	return s.client.Update(ctx, lease)
+}
+
+// emit all objects in namespaces which are assigned to this replica now, but were not before
+func (s *sharder) resync(ctx context.Context, oldMembers []string, members []string) error {
+	for _, target := range s.targets {
+		list := target.newList()
+		if err := s.client.List(ctx, list); err != nil {
+			return err
+		}
+		objects, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			object := object.(ctrlclient.Object)
+			namespace := object.GetNamespace()
+			if shardOwner(members, namespace) != s.identity || shardOwner(oldMembers, namespace) == s.identity {
+				continue
+			}
+			select {
+			case target.events <- event.GenericEvent{Object: object}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+// stager keeps track of staged reloads; workloads referencing the same config map or secret are reloaded in waves
+// (starting with canaries), where the next wave is only started once the rollouts of the previous wave have completed;
+// the state is shared between all handlers, and kept in memory only
+type stager struct {
+	batchSize   int
+	waveTimeout time.Duration
+	mutex       sync.Mutex
+	reloads     map[string]*stagedReload
+}
+
+type stagedReload struct {
+	// workloads of the current wave, and when the wave was started; the wave timeout is measured from the start of the wave,
+	// such that workloads whose reload is deferred (e.g. by maintenance windows) cannot block the staged reload forever
+	wave        map[types.UID]bool
+	waveStarted time.Time
+	// if the staged reload was aborted, the hashes the failed workloads were supposed to be reloaded to
+	failed map[types.UID]string
+}
+
+func newStager(batchSize int, waveTimeout time.Duration) *stager {
+	return &stager{
+		batchSize:   batchSize,
+		waveTimeout: waveTimeout,
+		reloads:     make(map[string]*stagedReload),
+	}
+}
+
+// determine which of the given workloads (all referencing the given object) may be reloaded now; workloads with in-place
+// reload strategies are not staged; a positive duration is returned if the request should be requeued to check the progress of the current wave
+func (h *genericHandler) stageReloads(ctx context.Context, kind string, namespace string, name string, objects []ctrlclient.Object, now time.Time) (map[types.UID]bool, time.Duration, error) {
+	log := ctrl.LoggerFrom(ctx)
+	s := h.stager
+
+	hashes := make(map[types.UID]string)
+	workloads := make(map[types.UID]ctrlclient.Object)
+	var outdated []ctrlclient.Object
+	for _, object := range objects {
+		if strategy, err := reloadStrategy(object); err != nil || strategy != reloader.StrategyRestart {
+			continue
+		}
+		hash, err := reloader.GenerateHashForObject(ctx, h.client, object)
+		if err != nil {
+			return nil, 0, err
+		}
+		hashes[object.GetUID()] = hash
+		workloads[object.GetUID()] = object
+		if reloader.AppliedHash(object, reloader.StrategyRestart) != hash {
+			outdated = append(outdated, object)
+		}
+	}
+
+	eligible := make(map[types.UID]bool)
+	for _, object := range objects {
+		eligible[object.GetUID()] = true
+	}
+	for _, object := range outdated {
+		eligible[object.GetUID()] = false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+	reload, ok := s.reloads[key]
+	if !ok {
+		if len(outdated) == 0 {
+			return eligible, 0, nil
+		}
+		reload = &stagedReload{}
+		s.reloads[key] = reload
+	}
+
+	if reload.failed != nil {
+		// an aborted staged reload is only resumed if the configuration of one of the failed workloads changes again
+		changed := false
+		for uid, hash := range reload.failed {
+			if hashes[uid] != hash {
+				changed = true
+			}
+		}
+		if !changed {
+			log.V(1).Info("skipping reloads because staged reload was aborted")
+			return eligible, 0, nil
+		}
+		log.Info("resuming aborted staged reload due to configuration change")
+		reload.failed = nil
+		reload.wave = nil
+	}
+
+	if reload.wave != nil {
+		complete := true
+		var failed []ctrlclient.Object
+		for uid := range reload.wave {
+			object, ok := workloads[uid]
+			if !ok {
+				// workload was deleted, or no longer references the object
+				continue
+			}
+			if reloader.AppliedHash(object, reloader.StrategyRestart) == hashes[uid] && rolloutComplete(object) {
+				continue
+			}
+			complete = false
+			if now.Sub(reload.waveStarted) > s.waveTimeout {
+				failed = append(failed, object)
+			}
+		}
+		if len(failed) > 0 {
+			log.Info("aborting staged reload because rollouts of the current wave did not complete in time", "failed", len(failed), "remaining", len(outdated))
+			reload.wave = nil
+			reload.failed = make(map[types.UID]string)
+			for _, object := range failed {
+				reload.failed[object.GetUID()] = hashes[object.GetUID()]
+				h.recorder.Eventf(object, corev1.EventTypeWarning, "StagedReloadAborted", "Staged reload due to change of referenced %s %s/%s aborted: reload did not complete within %s after the start of the wave", kind, namespace, name, s.waveTimeout)
+			}
+			return eligible, 0, nil
+		}
+		if !complete {
+			for uid := range reload.wave {
+				eligible[uid] = true
+			}
+			return eligible, retryInterval, nil
+		}
+		reload.wave = nil
+	}
+
+	if len(outdated) == 0 {
+		delete(s.reloads, key)
+		return eligible, 0, nil
+	}
+
+	// canaries go first; afterwards, the remaining workloads are reloaded in batches
+	sort.Slice(outdated, func(i, j int) bool {
+		return fmt.Sprintf("%T/%s", outdated[i], outdated[i].GetName()) < fmt.Sprintf("%T/%s", outdated[j], outdated[j].GetName())
+	})
+	var wave []ctrlclient.Object
+	for _, object := range outdated {
+		if object.GetLabels()[reloader.LabelCanary] == "true" {
+			wave = append(wave, object)
+		}
+	}
+	if len(wave) == 0 {
+		wave = outdated
+		if s.batchSize > 0 && len(wave) > s.batchSize {
+			wave = wave[:s.batchSize]
+		}
+	}
+	log.Info("starting reload wave", "size", len(wave), "remaining", len(outdated)-len(wave))
+	reload.wave = make(map[types.UID]bool)
+	reload.waveStarted = now
+	for _, object := range wave {
+		reload.wave[object.GetUID()] = true
+		eligible[object.GetUID()] = true
+	}
+	return eligible, retryInterval, nil
+}
@@ -0,0 +1,136 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test staged reloads", func() {
+	var configMap *corev1.ConfigMap
+	var canary *appsv1.Deployment
+	var deployments []*appsv1.Deployment
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "value"},
+		}
+		canary = buildDeployment("test", "canary", map[string]string{reloader.AnnotationConfigMaps: "config"})
+		canary.Labels = map[string]string{reloader.LabelCanary: "true"}
+		deployments = []*appsv1.Deployment{
+			buildDeployment("test", "test1", map[string]string{reloader.AnnotationConfigMaps: "config"}),
+			buildDeployment("test", "test2", map[string]string{reloader.AnnotationConfigMaps: "config"}),
+		}
+	})
+
+	newStagingHandler := func(batchSize int, waveTimeout time.Duration) *configMapHandler {
+		h := newTestHandler(Options{EnableStagedReloads: true}, configMap, canary, deployments[0], deployments[1])
+		h.stager = newStager(batchSize, waveTimeout)
+		return newConfigMapHandler(h)
+	}
+
+	// return the names of the deployments into which the controller injected a hash (emulating the webhook for them)
+	reloaded := func(h *configMapHandler) []string {
+		var names []string
+		for _, deployment := range append([]*appsv1.Deployment{canary}, deployments...) {
+			if admitInjectedHash(h.genericHandler, deployment) {
+				names = append(names, deployment.Name)
+			}
+		}
+		return names
+	}
+
+	It("should reload canaries first, and the remaining workloads in batches", func() {
+		h := newStagingHandler(1, time.Hour)
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(reloaded(h)).To(ConsistOf("canary"))
+
+		// next wave does not start before the rollouts of the current wave have completed
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(reloaded(h)).To(BeEmpty())
+
+		completeRollout(h.genericHandler, canary)
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(reloaded(h)).To(ConsistOf("test1"))
+
+		completeRollout(h.genericHandler, deployments[0])
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(reloaded(h)).To(ConsistOf("test2"))
+
+		completeRollout(h.genericHandler, deployments[1])
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(reloaded(h)).To(BeEmpty())
+		Expect(h.stager.reloads).To(BeEmpty())
+	})
+
+	It("should reload all workloads at once after the canaries without batch size", func() {
+		h := newStagingHandler(0, time.Hour)
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(reloaded(h)).To(ConsistOf("canary"))
+
+		completeRollout(h.genericHandler, canary)
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(reloaded(h)).To(ConsistOf("test1", "test2"))
+	})
+
+	It("should abort staged reloads if a wave does not complete in time, and resume on the next change", func() {
+		h := newStagingHandler(1, time.Millisecond)
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(reloaded(h)).To(ConsistOf("canary"))
+
+		time.Sleep(10 * time.Millisecond)
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(reloaded(h)).To(BeEmpty())
+		Expect(recordedEvents(h.recorder)).To(ContainElement(ContainSubstring("StagedReloadAborted")))
+
+		// aborted reloads stay aborted as long as the configuration does not change
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(reloaded(h)).To(BeEmpty())
+
+		configMap.Data["key"] = "other"
+		Expect(h.client.Update(ctx, configMap)).To(Succeed())
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(reloaded(h)).To(ConsistOf("canary"))
+	})
+
+	It("should abort staged reloads if a workload of the wave is not reloaded in time", func() {
+		canary.Annotations[reloader.AnnotationMaintenanceWindows] = inactiveMaintenanceWindow()
+		h := newStagingHandler(1, time.Millisecond)
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeNumerically(">", 0))
+		Expect(reloaded(h)).To(BeEmpty())
+
+		time.Sleep(10 * time.Millisecond)
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+		Expect(reloaded(h)).To(BeEmpty())
+		Expect(recordedEvents(h.recorder)).To(ContainElement(ContainSubstring("StagedReloadAborted")))
+	})
+
+	It("should not stage in-place reloads", func() {
+		deployments[0].Annotations[reloader.AnnotationStrategy] = reloader.StrategySignal
+		h := newStagingHandler(1, time.Hour)
+		h.executor = &fakeExecutor{}
+
+		Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(retryInterval))
+		Expect(reloaded(h)).To(ConsistOf("canary"))
+		Expect(h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployments[0]), deployments[0])).To(Succeed())
+		Expect(deployments[0].Annotations).To(HaveKey(reloader.AnnotationAppliedConfigHash))
+	})
+})
@@ -0,0 +1,389 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const defaultSignal = "HUP"
+
+const (
+	// how long to wait for containers to be restarted by the kubelet (restart-containers strategy)
+	containerRestartTimeout = 30 * time.Second
+	// how often to check the restart counts of restarted containers (by requeueing the request)
+	containerRestartCheckInterval = 2 * time.Second
+)
+
+var signalPattern = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// return the reload strategy of the given workload
+func reloadStrategy(object ctrlclient.Object) (string, error) {
+	switch strategy := object.GetAnnotations()[reloader.AnnotationStrategy]; strategy {
+	case "", reloader.StrategyRestart:
+		return reloader.StrategyRestart, nil
+	case reloader.StrategySignal, reloader.StrategyHTTP, reloader.StrategyRestartContainers:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid reload strategy: %s", strategy)
+	}
+}
+
+// record the given hash as applied (for in-place strategies)
+func (h *genericHandler) setAppliedHash(ctx context.Context, object ctrlclient.Object, hash string) error {
+	oldObject := object.DeepCopyObject().(ctrlclient.Object)
+	annotations := object.GetAnnotations()
+	annotations[reloader.AnnotationAppliedConfigHash] = hash
+	object.SetAnnotations(annotations)
+	return h.client.Patch(ctx, object, ctrlclient.MergeFrom(oldObject))
+}
+
+// reload the given workload in-place, according to the given (non-restart) strategy; a positive duration is returned
+// if the reload is still in progress, and the request should be requeued to complete it
+func (h *genericHandler) reloadInPlace(ctx context.Context, object ctrlclient.Object, strategy string, now time.Time) (time.Duration, error) {
+	switch strategy {
+	case reloader.StrategySignal:
+		return 0, h.signalPods(ctx, object)
+	case reloader.StrategyHTTP:
+		return 0, h.callReloadEndpoints(ctx, object)
+	case reloader.StrategyRestartContainers:
+		return h.restartContainers(ctx, object, now)
+	default:
+		return 0, fmt.Errorf("reload strategy %s does not support in-place reloads", strategy)
+	}
+}
+
+// send a signal to the processes of the target containers in all ready pods of the given workload
+func (h *genericHandler) signalPods(ctx context.Context, object ctrlclient.Object) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	annotations := object.GetAnnotations()
+	signal := strings.TrimPrefix(strings.ToUpper(annotations[reloader.AnnotationSignal]), "SIG")
+	if signal == "" {
+		signal = defaultSignal
+	}
+	if !signalPattern.MatchString(signal) {
+		return fmt.Errorf("invalid signal: %s", annotations[reloader.AnnotationSignal])
+	}
+	containerNames := reloader.SplitNames(annotations[reloader.AnnotationSignalContainers])
+
+	pods, err := h.listReadyPods(ctx, object)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			if len(containerNames) > 0 && !slices.Contains(containerNames, container.Name) {
+				continue
+			}
+			log.V(1).Info("sending signal", "pod", pod.Name, "container", container.Name, "signal", signal)
+			if _, err := h.executor.exec(ctx, pod.Namespace, pod.Name, container.Name, []string{"kill", "-" + signal, "1"}); err != nil {
+				log.Error(err, "error sending signal", "pod", pod.Name, "container", container.Name, "signal", signal)
+				h.recorder.Eventf(pod, corev1.EventTypeWarning, "ReloadSignalFailed", "Error sending signal %s to container %s: %s", signal, container.Name, err)
+				failed++
+				continue
+			}
+			h.recorder.Eventf(pod, corev1.EventTypeNormal, "ReloadSignalSent", "Sent signal %s to container %s due to configuration change", signal, container.Name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("error sending signal %s to %d container(s)", signal, failed)
+	}
+	return nil
+}
+
+// call the configured http reload endpoint on all ready pods of the given workload
+func (h *genericHandler) callReloadEndpoints(ctx context.Context, object ctrlclient.Object) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	annotations := object.GetAnnotations()
+	endpoint := reloadEndpoint{
+		scheme: strings.ToLower(annotations[reloader.AnnotationReloadScheme]),
+		port:   annotations[reloader.AnnotationReloadPort],
+		path:   annotations[reloader.AnnotationReloadPath],
+		method: strings.ToUpper(annotations[reloader.AnnotationReloadMethod]),
+	}
+	if endpoint.port == "" {
+		return fmt.Errorf("missing annotation %s", reloader.AnnotationReloadPort)
+	}
+	if endpoint.scheme == "" {
+		endpoint.scheme = "http"
+	} else if endpoint.scheme != "http" && endpoint.scheme != "https" {
+		return fmt.Errorf("invalid scheme: %s", endpoint.scheme)
+	}
+	if endpoint.path == "" {
+		endpoint.path = defaultReloadPath
+	} else if !strings.HasPrefix(endpoint.path, "/") {
+		endpoint.path = "/" + endpoint.path
+	}
+	if endpoint.method == "" {
+		endpoint.method = defaultReloadMethod
+	}
+
+	pods, err := h.listReadyPods(ctx, object)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, pod := range pods {
+		log.V(1).Info("calling reload endpoint", "pod", pod.Name, "method", endpoint.method, "port", endpoint.port, "path", endpoint.path)
+		if err := h.caller.call(ctx, pod, endpoint); err != nil {
+			log.Error(err, "error calling reload endpoint", "pod", pod.Name)
+			h.recorder.Eventf(pod, corev1.EventTypeWarning, "ReloadEndpointFailed", "Error calling reload endpoint %s %s on port %s: %s", endpoint.method, endpoint.path, endpoint.port, err)
+			failed++
+			continue
+		}
+		h.recorder.Eventf(pod, corev1.EventTypeNormal, "ReloadEndpointCalled", "Called reload endpoint %s %s on port %s due to configuration change", endpoint.method, endpoint.path, endpoint.port)
+	}
+	if failed > 0 {
+		return fmt.Errorf("error calling reload endpoint on %d pod(s)", failed)
+	}
+	return nil
+}
+
+// restart the containers of all ready pods of the given workload whose configuration hash has changed, by terminating their
+// main process (which makes the kubelet restart the container); the current per-container hashes are recorded on the workload
+// only after the restarts have been confirmed by increased restart counts, which are checked in subsequent calls (the returned duration
+// tells when to call again); if the restarts are not confirmed within containerRestartTimeout, an error is returned (and the caller falls back
+// to a rollout), since the main process might ignore the signal (or not be the application at all, e.g. with a shared process namespace)
+func (h *genericHandler) restartContainers(ctx context.Context, object ctrlclient.Object, now time.Time) (time.Duration, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	hashes, err := reloader.GenerateContainerHashesForObject(ctx, h.client, object)
+	if err != nil {
+		return 0, err
+	}
+
+	pending, ok := h.restarts.get(object)
+	if !ok || !maps.Equal(pending.hashes, hashes) {
+		restarts, err := h.terminateContainers(ctx, object, hashes)
+		if err != nil {
+			return 0, err
+		}
+		pending = pendingContainerRestarts{hashes: hashes, restarts: restarts, deadline: now.Add(containerRestartTimeout)}
+	}
+
+	pending.restarts = h.confirmContainerRestarts(ctx, pending.restarts)
+	if len(pending.restarts) > 0 {
+		if now.Before(pending.deadline) {
+			log.V(1).Info("waiting for restarted containers to come up", "containers", len(pending.restarts))
+			h.restarts.set(object, pending)
+			return containerRestartCheckInterval, nil
+		}
+		h.restarts.clear(object)
+		for _, restart := range pending.restarts {
+			log.Info("restart of container not confirmed", "pod", restart.pod.Name, "container", restart.containerName)
+			h.recorder.Eventf(restart.pod, corev1.EventTypeWarning, "ContainerRestartFailed", "Restart of container %s not confirmed within %s; the main process probably ignored the termination signal", restart.containerName, containerRestartTimeout)
+		}
+		return 0, fmt.Errorf("restart of %d container(s) not confirmed within %s", len(pending.restarts), containerRestartTimeout)
+	}
+	h.restarts.clear(object)
+
+	oldObject := object.DeepCopyObject().(ctrlclient.Object)
+	annotations := object.GetAnnotations()
+	annotations[reloader.AnnotationAppliedContainerConfigHashes] = reloader.FormatContainerHashes(hashes)
+	object.SetAnnotations(annotations)
+	return 0, h.client.Patch(ctx, object, ctrlclient.MergeFrom(oldObject))
+}
+
+// terminate the main process of those containers of the ready pods of the given workload whose applied hash differs from the given one,
+// and return the requested restarts
+func (h *genericHandler) terminateContainers(ctx context.Context, object ctrlclient.Object, hashes map[string]string) ([]containerRestart, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	appliedHashes, err := reloader.ParseContainerHashes(object.GetAnnotations()[reloader.AnnotationAppliedContainerConfigHashes])
+	if err != nil {
+		// restart all containers if the previously applied hashes are unknown
+		log.Error(err, "error parsing applied container configuration hashes; restarting all containers")
+		appliedHashes = map[string]string{}
+	}
+
+	pods, err := h.listReadyPods(ctx, object)
+	if err != nil {
+		return nil, err
+	}
+
+	var restarts []containerRestart
+	failed := 0
+	for _, pod := range pods {
+		var containers []corev1.Container
+		for _, container := range pod.Spec.InitContainers {
+			// only sidecar containers are running; regular init containers have already terminated
+			if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+				containers = append(containers, container)
+			}
+		}
+		containers = append(containers, pod.Spec.Containers...)
+		for _, container := range containers {
+			if hash, ok := appliedHashes[container.Name]; ok && hash == hashes[container.Name] {
+				continue
+			}
+			log.V(1).Info("restarting container", "pod", pod.Name, "container", container.Name)
+			restartCount := containerRestartCount(pod, container.Name)
+			if _, err := h.executor.exec(ctx, pod.Namespace, pod.Name, container.Name, []string{"kill", "1"}); err != nil {
+				log.Error(err, "error restarting container", "pod", pod.Name, "container", container.Name)
+				h.recorder.Eventf(pod, corev1.EventTypeWarning, "ContainerRestartFailed", "Error restarting container %s: %s", container.Name, err)
+				failed++
+				continue
+			}
+			restarts = append(restarts, containerRestart{pod: pod, containerName: container.Name, restartCount: restartCount})
+		}
+	}
+	if failed > 0 {
+		return nil, fmt.Errorf("error restarting %d container(s)", failed)
+	}
+	return restarts, nil
+}
+
+// a container which was requested to restart, along with its restart count before the request
+type containerRestart struct {
+	pod           *corev1.Pod
+	containerName string
+	restartCount  int32
+}
+
+// the container restarts requested for a workload, which are not yet confirmed
+type pendingContainerRestarts struct {
+	// the per-container hashes being applied
+	hashes   map[string]string
+	restarts []containerRestart
+	deadline time.Time
+}
+
+// containerRestarts keeps track of the pending container restarts per workload between the calls of restartContainers;
+// the state is shared between all handlers, and kept in memory (after a restart of the controller, the containers are restarted again)
+type containerRestarts struct {
+	mutex   sync.Mutex
+	entries map[types.UID]pendingContainerRestarts
+}
+
+func newContainerRestarts() *containerRestarts {
+	return &containerRestarts{entries: make(map[types.UID]pendingContainerRestarts)}
+}
+
+func (r *containerRestarts) get(object ctrlclient.Object) (pendingContainerRestarts, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	pending, ok := r.entries[object.GetUID()]
+	return pending, ok
+}
+
+func (r *containerRestarts) set(object ctrlclient.Object, pending pendingContainerRestarts) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries[object.GetUID()] = pending
+}
+
+func (r *containerRestarts) clear(object ctrlclient.Object) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.entries, object.GetUID())
+}
+
+// check the restart counts of the given containers, and return those whose restart is not yet confirmed,
+// that is whose restart count has not increased (and whose pod still exists)
+func (h *genericHandler) confirmContainerRestarts(ctx context.Context, restarts []containerRestart) []containerRestart {
+	log := ctrl.LoggerFrom(ctx)
+
+	var pending []containerRestart
+	for _, restart := range restarts {
+		pod := &corev1.Pod{}
+		if err := h.reader.Get(ctx, ctrlclient.ObjectKeyFromObject(restart.pod), pod); err != nil {
+			if apierrors.IsNotFound(err) {
+				// a replacement pod starts with the current configuration anyway
+				continue
+			}
+			log.Error(err, "error reading pod", "pod", restart.pod.Name)
+			pending = append(pending, restart)
+			continue
+		}
+		if pod.UID != restart.pod.UID || containerRestartCount(pod, restart.containerName) > restart.restartCount {
+			h.recorder.Eventf(pod, corev1.EventTypeNormal, "ContainerRestarted", "Restarted container %s due to configuration change", restart.containerName)
+			continue
+		}
+		pending = append(pending, restart)
+	}
+	return pending
+}
+
+// return the restart count of the specified (regular or sidecar) container of the given pod
+func containerRestartCount(pod *corev1.Pod, containerName string) int32 {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses} {
+		for _, status := range statuses {
+			if status.Name == containerName {
+				return status.RestartCount
+			}
+		}
+	}
+	return 0
+}
+
+// list the running, ready and not terminating pods of the given workload
+func (h *genericHandler) listReadyPods(ctx context.Context, object ctrlclient.Object) ([]*corev1.Pod, error) {
+	var labelSelector *metav1.LabelSelector
+	switch obj := object.(type) {
+	// add additional workload types here
+	case *appsv1.Deployment:
+		labelSelector = obj.Spec.Selector
+	case *appsv1.StatefulSet:
+		labelSelector = obj.Spec.Selector
+	case *appsv1.DaemonSet:
+		labelSelector = obj.Spec.Selector
+	default:
+		return nil, fmt.Errorf("unsupported workload type: %T", object)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// note: pods are read uncached, to avoid caching all pods of the cluster
+	podList := &corev1.PodList{}
+	if err := h.reader.List(ctx, podList, ctrlclient.InNamespace(object.GetNamespace()), ctrlclient.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	var pods []*corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning || !podReady(pod) {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+// fakeExecutor records the executed commands (as "<pod>/<container>: <command>"), and fails for the configured containers
+type fakeExecutor struct {
+	mutex    sync.Mutex
+	commands []string
+	failing  map[string]bool
+}
+
+var _ commandExecutor = &fakeExecutor{}
+
+func (e *fakeExecutor) exec(ctx context.Context, namespace string, podName string, containerName string, command []string) (string, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.commands = append(e.commands, fmt.Sprintf("%s/%s: %s", podName, containerName, strings.Join(command, " ")))
+	if e.failing[containerName] {
+		return "", fmt.Errorf("command terminated with exit code 1")
+	}
+	return "", nil
+}
+
+func buildPod(namespace string, name string, labels map[string]string, ready bool, containerNames ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    labels,
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      "127.0.0.1",
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+		},
+	}
+	if ready {
+		pod.Status.Conditions[0].Status = corev1.ConditionTrue
+	}
+	for _, containerName := range containerNames {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: containerName, Image: containerName})
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{Name: containerName})
+	}
+	return pod
+}
+
+var _ = Describe("Test reload strategies", func() {
+	var configMap *corev1.ConfigMap
+	var deployment *appsv1.Deployment
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "value"},
+		}
+		deployment = buildDeployment("test", "test", map[string]string{reloader.AnnotationConfigMaps: "config"})
+	})
+
+	DescribeTable("reloadStrategy",
+		func(strategy string, expected string, valid bool) {
+			deployment.Annotations[reloader.AnnotationStrategy] = strategy
+			s, err := reloadStrategy(deployment)
+			if !valid {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(Equal(expected))
+		},
+		Entry("default", "", reloader.StrategyRestart, true),
+		Entry("restart", reloader.StrategyRestart, reloader.StrategyRestart, true),
+		Entry("signal", reloader.StrategySignal, reloader.StrategySignal, true),
+		Entry("http", reloader.StrategyHTTP, reloader.StrategyHTTP, true),
+		Entry("restart-containers", reloader.StrategyRestartContainers, reloader.StrategyRestartContainers, true),
+		Entry("invalid", "invalid", "", false),
+	)
+
+	Context("signal strategy", func() {
+		var executor *fakeExecutor
+		var pods []*corev1.Pod
+
+		BeforeEach(func() {
+			deployment.Annotations[reloader.AnnotationStrategy] = reloader.StrategySignal
+			executor = &fakeExecutor{}
+			pods = []*corev1.Pod{
+				buildPod("test", "test-1", map[string]string{"app": "test"}, true, "app", "sidecar"),
+				buildPod("test", "test-2", map[string]string{"app": "test"}, false, "app", "sidecar"),
+				buildPod("test", "other", map[string]string{"app": "other"}, true, "app"),
+			}
+		})
+
+		newSignalHandler := func() *configMapHandler {
+			h := newTestHandler(Options{}, configMap, deployment, pods[0], pods[1], pods[2])
+			h.executor = executor
+			return newConfigMapHandler(h)
+		}
+
+		It("should signal all containers of the ready pods, and record the applied hash", func() {
+			h := newSignalHandler()
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+
+			Expect(executor.commands).To(ConsistOf("test-1/app: kill -HUP 1", "test-1/sidecar: kill -HUP 1"))
+			Expect(injectedHash(h.genericHandler, deployment)).To(BeEmpty())
+			hash, err := reloader.GenerateHashForObject(ctx, h.client, deployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployment.Annotations[reloader.AnnotationAppliedConfigHash]).To(Equal(hash))
+
+			// no further signals as long as the configuration does not change
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			Expect(executor.commands).To(HaveLen(2))
+		})
+
+		It("should send the configured signal to the configured containers", func() {
+			deployment.Annotations[reloader.AnnotationSignal] = "SIGUSR1"
+			deployment.Annotations[reloader.AnnotationSignalContainers] = "sidecar"
+			h := newSignalHandler()
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+
+			Expect(executor.commands).To(ConsistOf("test-1/sidecar: kill -USR1 1"))
+		})
+
+		It("should reject invalid signals", func() {
+			deployment.Annotations[reloader.AnnotationSignal] = "HUP; rm -rf /"
+			h := newSignalHandler()
+			_, err := h.handle(ctx, "ConfigMap", "test", "config")
+			Expect(err).To(MatchError(ContainSubstring("invalid signal")))
+
+			Expect(executor.commands).To(BeEmpty())
+			Expect(injectedHash(h.genericHandler, deployment)).To(BeEmpty())
+			Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationAppliedConfigHash))
+		})
+
+		It("should fail without recording the applied hash if signals cannot be sent", func() {
+			executor.failing = map[string]bool{"sidecar": true}
+			h := newSignalHandler()
+			_, err := h.handle(ctx, "ConfigMap", "test", "config")
+			Expect(err).To(HaveOccurred())
+
+			Expect(executor.commands).To(ConsistOf("test-1/app: kill -HUP 1", "test-1/sidecar: kill -HUP 1"))
+			Expect(injectedHash(h.genericHandler, deployment)).To(BeEmpty())
+			Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationAppliedConfigHash))
+		})
+
+		It("should defer signals until mounted volumes are synced", func() {
+			h := newTestHandler(Options{VolumeSyncDelay: time.Minute}, configMap, deployment, pods[0])
+			h.executor = executor
+			requeueAfter, err := newConfigMapHandler(h).handle(ctx, "ConfigMap", "test", "config")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requeueAfter).To(Equal(time.Minute))
+			Expect(executor.commands).To(BeEmpty())
+		})
+	})
+
+	Context("http strategy", func() {
+		var server *httptest.Server
+		var requests []string
+		var status int
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			requests = nil
+			status = http.StatusOK
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				w.WriteHeader(status)
+			}))
+			u, err := url.Parse(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment.Annotations[reloader.AnnotationStrategy] = reloader.StrategyHTTP
+			deployment.Annotations[reloader.AnnotationReloadPort] = "http"
+			pod = buildPod("test", "test-1", map[string]string{"app": "test"}, true, "app")
+			port, err := strconv.Atoi(u.Port())
+			Expect(err).NotTo(HaveOccurred())
+			pod.Spec.Containers[0].Ports = []corev1.ContainerPort{{Name: "http", ContainerPort: int32(port)}}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newHTTPHandler := func() *configMapHandler {
+			h := newTestHandler(Options{}, configMap, deployment, pod)
+			h.caller = &endpointCaller{httpClient: server.Client(), timeout: time.Second, retries: 1}
+			return newConfigMapHandler(h)
+		}
+
+		It("should call the reload endpoint of the ready pods, and record the applied hash", func() {
+			h := newHTTPHandler()
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+
+			Expect(requests).To(ConsistOf("POST /-/reload"))
+			Expect(injectedHash(h.genericHandler, deployment)).To(BeEmpty())
+			Expect(deployment.Annotations).To(HaveKey(reloader.AnnotationAppliedConfigHash))
+		})
+
+		It("should call the configured method and path", func() {
+			deployment.Annotations[reloader.AnnotationReloadMethod] = "put"
+			deployment.Annotations[reloader.AnnotationReloadPath] = "actuator/refresh"
+			h := newHTTPHandler()
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+
+			Expect(requests).To(ConsistOf("PUT /actuator/refresh"))
+		})
+
+		It("should retry, and fall back to a restart if the reload endpoint fails", func() {
+			status = http.StatusInternalServerError
+			h := newHTTPHandler()
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+
+			Expect(requests).To(HaveLen(2))
+			Expect(injectedHash(h.genericHandler, deployment)).NotTo(BeEmpty())
+			Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationAppliedConfigHash))
+		})
+
+		It("should fall back to a restart if the reload port is missing", func() {
+			delete(deployment.Annotations, reloader.AnnotationReloadPort)
+			h := newHTTPHandler()
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+
+			Expect(requests).To(BeEmpty())
+			Expect(injectedHash(h.genericHandler, deployment)).NotTo(BeEmpty())
+		})
+	})
+
+	Context("restart-containers strategy", func() {
+		var executor *fakeExecutor
+		var pod *corev1.Pod
+		var h *configMapHandler
+
+		BeforeEach(func() {
+			deployment.Annotations[reloader.AnnotationStrategy] = reloader.StrategyRestartContainers
+			pod = buildPod("test", "test-1", map[string]string{"app": "test"}, true, "app", "sidecar")
+			deployment.Spec.Template.Spec.Containers = pod.Spec.Containers
+			executor = &fakeExecutor{}
+			h = newConfigMapHandler(newTestHandler(Options{}, configMap, deployment, pod))
+			h.executor = executor
+		})
+
+		It("should restart the containers, and record the applied hashes once the restarts are confirmed", func() {
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(containerRestartCheckInterval))
+			Expect(executor.commands).To(ConsistOf("test-1/app: kill 1", "test-1/sidecar: kill 1"))
+			Expect(h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+			Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationAppliedConfigHash))
+
+			// emulate the kubelet restarting the containers
+			Expect(h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(pod), pod)).To(Succeed())
+			for i := range pod.Status.ContainerStatuses {
+				pod.Status.ContainerStatuses[i].RestartCount++
+			}
+			Expect(h.client.Status().Update(ctx, pod)).To(Succeed())
+
+			// the restarts are confirmed when the request is requeued, without restarting the containers again
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			Expect(executor.commands).To(HaveLen(2))
+			Expect(injectedHash(h.genericHandler, deployment)).To(BeEmpty())
+			Expect(deployment.Annotations).To(HaveKey(reloader.AnnotationAppliedConfigHash))
+			hashes, err := reloader.ParseContainerHashes(deployment.Annotations[reloader.AnnotationAppliedContainerConfigHashes])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hashes).To(HaveKey("app"))
+			Expect(hashes).To(HaveKey("sidecar"))
+		})
+
+		It("should fall back to a rollout if the restarts are not confirmed within the timeout", func() {
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(containerRestartCheckInterval))
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(Equal(containerRestartCheckInterval))
+			Expect(executor.commands).To(HaveLen(2))
+			Expect(injectedHash(h.genericHandler, deployment)).To(BeEmpty())
+
+			pending, ok := h.restarts.get(deployment)
+			Expect(ok).To(BeTrue())
+			pending.deadline = time.Now().Add(-time.Second)
+			h.restarts.set(deployment, pending)
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			Expect(injectedHash(h.genericHandler, deployment)).NotTo(BeEmpty())
+			Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationAppliedContainerConfigHashes))
+			_, ok = h.restarts.get(deployment)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should fall back to a rollout if containers cannot be restarted", func() {
+			executor.failing = map[string]bool{"app": true}
+
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			Expect(injectedHash(h.genericHandler, deployment)).NotTo(BeEmpty())
+			Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationAppliedConfigHash))
+			Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationAppliedContainerConfigHashes))
+		})
+	})
+})
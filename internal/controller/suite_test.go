@@ -0,0 +1,151 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	podreloaderv1alpha1 "github.com/sap/pod-reloader/api/v1alpha1"
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var ctx context.Context
+var cancel context.CancelFunc
+
+func TestController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Controller Suite")
+}
+
+var _ = BeforeSuite(func() {
+	By("setting up context")
+	ctx, cancel = context.WithCancel(context.TODO())
+})
+
+var _ = AfterSuite(func() {
+	By("cancelling context")
+	cancel()
+})
+
+// create a handler operating on a fake client populated with the given objects; note that the fake client does not run the webhook,
+// so injected hashes remain on the workload, instead of being moved to the pod template
+func newTestHandler(options Options, objects ...ctrlclient.Object) genericHandler {
+	cli := newTestClient(objects...)
+	return genericHandler{
+		client:    cli,
+		reader:    cli,
+		recorder:  record.NewFakeRecorder(100),
+		options:   options,
+		throttle:  newThrottle(cli, options.MinReloadInterval, options.MaxConcurrentRollouts),
+		deferrals: newDeferrals(),
+		restarts:  newContainerRestarts(),
+	}
+}
+
+// create a fake client populated with the given objects
+func newTestClient(objects ...ctrlclient.Object) ctrlclient.WithWatch {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(podreloaderv1alpha1.AddToScheme(scheme))
+	return fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		// add additional workload types here
+		WithStatusSubresource(&appsv1.Deployment{}, &appsv1.StatefulSet{}, &appsv1.DaemonSet{}, &podreloaderv1alpha1.ReloadStatus{}).
+		WithIndex(newPodMetadata(), podReferenceIndex, indexPodReferences).
+		Build()
+}
+
+func buildDeployment(namespace string, name string, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			UID:         types.UID(fmt.Sprintf("Deployment/%s/%s", namespace, name)),
+			Annotations: annotations,
+			Generation:  1,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: "app"}},
+				},
+			},
+		},
+	}
+}
+
+// return the hash injected into the given workload by the controller (read through the handler's client)
+func injectedHash(h genericHandler, object ctrlclient.Object) string {
+	ExpectWithOffset(1, h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(object), object)).To(Succeed())
+	return object.GetAnnotations()[reloader.AnnotationConfigHash]
+}
+
+// emulate the webhook (which is not run by the fake client) by moving the hash injected into the given deployment to its pod template
+// (increasing the generation, as the api server would do); returns whether a hash was injected
+func admitInjectedHash(h genericHandler, deployment *appsv1.Deployment) bool {
+	ExpectWithOffset(1, h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+	hash, ok := deployment.Annotations[reloader.AnnotationConfigHash]
+	if !ok {
+		return false
+	}
+	delete(deployment.Annotations, reloader.AnnotationConfigHash)
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = make(map[string]string)
+	}
+	deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash] = hash
+	deployment.Generation++
+	ExpectWithOffset(1, h.client.Update(ctx, deployment)).To(Succeed())
+	return true
+}
+
+// mark the current rollout of the given deployment as complete
+func completeRollout(h genericHandler, deployment *appsv1.Deployment) {
+	ExpectWithOffset(1, h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+	deployment.Status.ObservedGeneration = deployment.Generation
+	deployment.Status.Replicas = 1
+	deployment.Status.UpdatedReplicas = 1
+	deployment.Status.AvailableReplicas = 1
+	ExpectWithOffset(1, h.client.Status().Update(ctx, deployment)).To(Succeed())
+}
+
+// return (and consume) the events recorded by the given (fake) recorder so far
+func recordedEvents(recorder record.EventRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.(*record.FakeRecorder).Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// a maintenance window of one minute, starting in twelve hours (so the current time is outside of it)
+func inactiveMaintenanceWindow() string {
+	start := time.Now().UTC().Add(12 * time.Hour)
+	return fmt.Sprintf("%d %d * * * 1m", start.Minute(), start.Hour())
+}
@@ -0,0 +1,244 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+// throttle keeps track of reloads triggered by the controller, in order to enforce a minimum interval
+// between reloads of the same workload, a maximum number of concurrently progressing rollouts, and a delay
+// for in-place reloads (allowing kubelet to sync mounted volumes); the state is shared between all handlers,
+// and kept in memory; in addition, rollouts are marked on the workloads (see markRollout), such that rollouts
+// triggered by other replicas (with sharding), or before a restart, count towards the maximum number of rollouts as well
+type throttle struct {
+	client                ctrlclient.Client
+	minReloadInterval     time.Duration
+	maxConcurrentRollouts int
+	mutex                 sync.Mutex
+	notBefore             map[types.UID]time.Time
+	rollouts              map[types.UID]trackedRollout
+	changes               map[types.UID]observedChange
+	evictions             map[string]time.Time
+}
+
+type observedChange struct {
+	hash string
+	time time.Time
+}
+
+type trackedRollout struct {
+	object     ctrlclient.Object
+	generation int64
+}
+
+func newThrottle(client ctrlclient.Client, minReloadInterval time.Duration, maxConcurrentRollouts int) *throttle {
+	return &throttle{
+		client:                client,
+		minReloadInterval:     minReloadInterval,
+		maxConcurrentRollouts: maxConcurrentRollouts,
+		notBefore:             make(map[types.UID]time.Time),
+		rollouts:              make(map[types.UID]trackedRollout),
+		changes:               make(map[types.UID]observedChange),
+		evictions:             make(map[string]time.Time),
+	}
+}
+
+// return how long a reload of the given workload has to be delayed due to the minimum reload interval
+func (t *throttle) reloadDelay(object ctrlclient.Object, now time.Time) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for uid, notBefore := range t.notBefore {
+		if !notBefore.After(now) {
+			delete(t.notBefore, uid)
+		}
+	}
+	if notBefore, ok := t.notBefore[object.GetUID()]; ok {
+		return notBefore.Sub(now)
+	}
+	return 0
+}
+
+// return how long an in-place reload of the given workload to the given hash has to be delayed, such that
+// at least the specified delay has passed since the hash was first observed
+func (t *throttle) syncDelay(object ctrlclient.Object, hash string, delay time.Duration, now time.Time) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for uid, change := range t.changes {
+		if now.Sub(change.time) > 24*time.Hour {
+			delete(t.changes, uid)
+		}
+	}
+	change, ok := t.changes[object.GetUID()]
+	if !ok || change.hash != hash {
+		change = observedChange{hash: hash, time: now}
+		t.changes[object.GetUID()] = change
+	}
+	return change.time.Add(delay).Sub(now)
+}
+
+// return how long the next pod eviction in the given namespace has to be delayed
+func (t *throttle) evictionDelay(namespace string, now time.Time) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for ns, notBefore := range t.evictions {
+		if !notBefore.After(now) {
+			delete(t.evictions, ns)
+		}
+	}
+	if notBefore, ok := t.evictions[namespace]; ok {
+		return notBefore.Sub(now)
+	}
+	return 0
+}
+
+// record a pod eviction in the given namespace
+func (t *throttle) recordEviction(namespace string, interval time.Duration, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if interval > 0 {
+		t.evictions[namespace] = now.Add(interval)
+	}
+}
+
+// try to reserve a rollout slot for the given workload; returns false if the maximum number
+// of concurrent rollouts is reached; a successful call must be followed by either recordReload() or releaseRollout()
+func (t *throttle) acquireRollout(ctx context.Context, object ctrlclient.Object) (bool, error) {
+	if t.maxConcurrentRollouts <= 0 {
+		return true, nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, ok := t.rollouts[object.GetUID()]; !ok {
+		for uid, rollout := range t.rollouts {
+			current := rollout.object.DeepCopyObject().(ctrlclient.Object)
+			if err := t.client.Get(ctx, ctrlclient.ObjectKeyFromObject(current), current); err != nil {
+				if apierrors.IsNotFound(err) {
+					delete(t.rollouts, uid)
+					continue
+				}
+				return false, err
+			}
+			if current.GetUID() != uid || (current.GetGeneration() >= rollout.generation && rolloutComplete(current)) {
+				delete(t.rollouts, uid)
+			}
+		}
+		rollouts := len(t.rollouts)
+		if rollouts < t.maxConcurrentRollouts {
+			markedRollouts, err := t.countMarkedRollouts(ctx)
+			if err != nil {
+				return false, err
+			}
+			rollouts += markedRollouts
+		}
+		if rollouts >= t.maxConcurrentRollouts {
+			return false, nil
+		}
+	}
+	t.rollouts[object.GetUID()] = trackedRollout{object: object.DeepCopyObject().(ctrlclient.Object), generation: math.MaxInt64}
+	return true, nil
+}
+
+// count the workloads marked as being rolled out (see markRollout) whose rollout is still in progress, except for those tracked in memory
+func (t *throttle) countMarkedRollouts(ctx context.Context) (int, error) {
+	var objects []ctrlclient.Object
+	// add additional workload types here
+	deploymentList := &appsv1.DeploymentList{}
+	if err := t.client.List(ctx, deploymentList); err != nil {
+		return 0, err
+	}
+	for i := range deploymentList.Items {
+		objects = append(objects, &deploymentList.Items[i])
+	}
+	statefulSetList := &appsv1.StatefulSetList{}
+	if err := t.client.List(ctx, statefulSetList); err != nil {
+		return 0, err
+	}
+	for i := range statefulSetList.Items {
+		objects = append(objects, &statefulSetList.Items[i])
+	}
+	daemonSetList := &appsv1.DaemonSetList{}
+	if err := t.client.List(ctx, daemonSetList); err != nil {
+		return 0, err
+	}
+	for i := range daemonSetList.Items {
+		objects = append(objects, &daemonSetList.Items[i])
+	}
+
+	count := 0
+	for _, object := range objects {
+		if _, ok := t.rollouts[object.GetUID()]; ok {
+			continue
+		}
+		// the marker only counts as long as the generation of the rollout is current (i.e. was not superseded by other changes)
+		if object.GetAnnotations()[reloader.AnnotationRolloutGeneration] == strconv.FormatInt(object.GetGeneration(), 10) && !rolloutComplete(object) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// mark the given workload (in memory) as being rolled out, with the generation the workload will have after the pending update of its pod template;
+// this is a no-op unless the number of concurrent rollouts is limited
+func (t *throttle) markRollout(object ctrlclient.Object) {
+	if t.maxConcurrentRollouts <= 0 {
+		return
+	}
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[reloader.AnnotationRolloutGeneration] = strconv.FormatInt(object.GetGeneration()+1, 10)
+	object.SetAnnotations(annotations)
+}
+
+// release a rollout slot previously acquired through acquireRollout()
+func (t *throttle) releaseRollout(object ctrlclient.Object) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.rollouts, object.GetUID())
+}
+
+// record a reload of the given workload; object is expected to be the workload as returned by the update call
+func (t *throttle) recordReload(object ctrlclient.Object, interval time.Duration, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if interval > 0 {
+		t.notBefore[object.GetUID()] = now.Add(interval)
+	}
+	delete(t.changes, object.GetUID())
+	if rollout, ok := t.rollouts[object.GetUID()]; ok {
+		rollout.generation = object.GetGeneration()
+		t.rollouts[object.GetUID()] = rollout
+	}
+}
+
+// return the minimum reload interval for the given workload
+func (t *throttle) reloadInterval(object ctrlclient.Object) (time.Duration, error) {
+	if s := object.GetAnnotations()[reloader.AnnotationMinReloadInterval]; s != "" {
+		return time.ParseDuration(s)
+	}
+	return t.minReloadInterval, nil
+}
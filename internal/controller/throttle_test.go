@@ -0,0 +1,145 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test throttling", func() {
+	var configMap *corev1.ConfigMap
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "value1"},
+		}
+	})
+
+	updateConfigMap := func(h genericHandler, value string) {
+		configMap.Data["key"] = value
+		ExpectWithOffset(1, h.client.Update(ctx, configMap)).To(Succeed())
+	}
+
+	Context("minimum reload interval", func() {
+		It("should defer reloads within the minimum reload interval", func() {
+			deployment := buildDeployment("test", "test", map[string]string{reloader.AnnotationConfigMaps: "config"})
+			h := newConfigMapHandler(newTestHandler(Options{MinReloadInterval: time.Hour}, configMap, deployment))
+
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			hash := injectedHash(h.genericHandler, deployment)
+			Expect(hash).NotTo(BeEmpty())
+
+			updateConfigMap(h.genericHandler, "value2")
+			requeueAfter, err := h.handle(ctx, "ConfigMap", "test", "config")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requeueAfter).To(BeNumerically(">", 59*time.Minute))
+			Expect(requeueAfter).To(BeNumerically("<=", time.Hour))
+			Expect(injectedHash(h.genericHandler, deployment)).To(Equal(hash))
+		})
+
+		It("should respect the minimum reload interval annotation", func() {
+			deployment := buildDeployment("test", "test", map[string]string{
+				reloader.AnnotationConfigMaps:        "config",
+				reloader.AnnotationMinReloadInterval: "5m",
+			})
+			h := newConfigMapHandler(newTestHandler(Options{MinReloadInterval: time.Hour}, configMap, deployment))
+
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			updateConfigMap(h.genericHandler, "value2")
+			requeueAfter, err := h.handle(ctx, "ConfigMap", "test", "config")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requeueAfter).To(BeNumerically(">", 4*time.Minute))
+			Expect(requeueAfter).To(BeNumerically("<=", 5*time.Minute))
+		})
+
+		It("should fall back to the default for invalid minimum reload interval annotations", func() {
+			deployment := buildDeployment("test", "test", map[string]string{
+				reloader.AnnotationConfigMaps:        "config",
+				reloader.AnnotationMinReloadInterval: "invalid",
+			})
+			h := newConfigMapHandler(newTestHandler(Options{}, configMap, deployment))
+
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			hash := injectedHash(h.genericHandler, deployment)
+			updateConfigMap(h.genericHandler, "value2")
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			Expect(injectedHash(h.genericHandler, deployment)).NotTo(Equal(hash))
+		})
+	})
+
+	Context("concurrent rollouts", func() {
+		It("should defer rollouts beyond the maximum number of concurrent rollouts", func() {
+			deployment1 := buildDeployment("test", "test1", map[string]string{reloader.AnnotationConfigMaps: "config"})
+			deployment2 := buildDeployment("test", "test2", map[string]string{reloader.AnnotationConfigMaps: "config"})
+			h := newConfigMapHandler(newTestHandler(Options{MaxConcurrentRollouts: 1}, configMap, deployment1, deployment2))
+
+			requeueAfter, err := h.handle(ctx, "ConfigMap", "test", "config")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requeueAfter).To(Equal(retryInterval))
+			Expect(injectedHash(h.genericHandler, deployment1)).NotTo(BeEmpty())
+			Expect(injectedHash(h.genericHandler, deployment2)).To(BeEmpty())
+
+			completeRollout(h.genericHandler, deployment1)
+			Expect(h.handle(ctx, "ConfigMap", "test", "config")).To(BeZero())
+			Expect(injectedHash(h.genericHandler, deployment2)).NotTo(BeEmpty())
+		})
+
+		It("should count rollouts marked by other replicas", func() {
+			// a rollout triggered by another replica (or before a restart), still in progress
+			marked := buildDeployment("test", "marked", map[string]string{reloader.AnnotationRolloutGeneration: "1"})
+			other := buildDeployment("test", "other", nil)
+			h := newTestHandler(Options{MaxConcurrentRollouts: 1}, marked, other)
+
+			ok, err := h.throttle.acquireRollout(ctx, other)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should ignore marked rollouts which completed or were superseded", func() {
+			completed := buildDeployment("test", "completed", map[string]string{reloader.AnnotationRolloutGeneration: "1"})
+			completed.Status.ObservedGeneration = 1
+			completed.Status.Replicas = 1
+			completed.Status.UpdatedReplicas = 1
+			completed.Status.AvailableReplicas = 1
+			superseded := buildDeployment("test", "superseded", map[string]string{reloader.AnnotationRolloutGeneration: "1"})
+			superseded.Generation = 2
+			other := buildDeployment("test", "other", nil)
+			h := newTestHandler(Options{MaxConcurrentRollouts: 1}, completed, superseded, other)
+
+			ok, err := h.throttle.acquireRollout(ctx, other)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should mark triggered rollouts with the upcoming generation", func() {
+			deployment := buildDeployment("test", "test", nil)
+			h := newTestHandler(Options{MaxConcurrentRollouts: 1}, deployment)
+
+			Expect(h.reloadObjects(ctx, "ConfigMap", "test", "config", []ctrlclient.Object{deployment}, true, time.Now())).To(BeZero())
+			Expect(h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+			Expect(deployment.Annotations).To(HaveKeyWithValue(reloader.AnnotationRolloutGeneration, "2"))
+		})
+
+		It("should not mark rollouts if the number of rollouts is not limited", func() {
+			deployment := buildDeployment("test", "test", nil)
+			h := newTestHandler(Options{}, deployment)
+
+			Expect(h.reloadObjects(ctx, "ConfigMap", "test", "config", []ctrlclient.Object{deployment}, true, time.Now())).To(BeZero())
+			Expect(h.client.Get(ctx, ctrlclient.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+			Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationRolloutGeneration))
+		})
+	})
+})
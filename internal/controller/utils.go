@@ -5,11 +5,21 @@ SPDX-License-Identifier: Apache-2.0
 
 package controller
 
-func contains[T comparable](s []T, x T) bool {
-	for _, y := range s {
-		if y == x {
-			return true
-		}
+import (
+	"time"
+)
+
+// return the smaller of two durations, where zero means unset
+func minDuration(d1 time.Duration, d2 time.Duration) time.Duration {
+	if d1 <= 0 || (d2 > 0 && d2 < d1) {
+		return d2
+	}
+	return d1
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
 	}
-	return false
+	return t.UTC().Format(time.RFC3339)
 }
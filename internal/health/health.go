@@ -0,0 +1,136 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// name of the controller processing the heartbeat probes
+	heartbeatControllerName = "heartbeat"
+	// interval in which heartbeat probes are sent
+	heartbeatInterval = 10 * time.Second
+	// time after which an unprocessed heartbeat probe is considered as failure
+	heartbeatTimeout = 1 * time.Minute
+	// timeout for a single cache check
+	cacheCheckTimeout = 1 * time.Second
+)
+
+// CacheSyncChecker returns a checker which succeeds once all informers of the given cache have synced.
+func CacheSyncChecker(cache cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), cacheCheckTimeout)
+		defer cancel()
+		if !cache.WaitForCacheSync(ctx) {
+			return fmt.Errorf("cache not synced")
+		}
+		return nil
+	}
+}
+
+// Heartbeat is a runnable which periodically sends probe events through a controller of the manager; the time of the oldest
+// unprocessed probe is recorded, and cleared when the controller processes a probe. Its checker fails if a probe stays unprocessed
+// for some time, indicating that the manager's controllers are stalled (or have stopped).
+type Heartbeat struct {
+	cache   cache.Cache
+	events  chan event.GenericEvent
+	pending atomic.Int64
+}
+
+var _ manager.Runnable = &Heartbeat{}
+var _ manager.LeaderElectionRunnable = &Heartbeat{}
+var _ reconcile.Reconciler = &Heartbeat{}
+
+// NewHeartbeat creates a heartbeat runnable for the given cache.
+func NewHeartbeat(cache cache.Cache) *Heartbeat {
+	return &Heartbeat{cache: cache, events: make(chan event.GenericEvent, 1)}
+}
+
+// SetupWithManager adds the heartbeat, and the controller processing its probes, to the given manager;
+// both run on all replicas.
+func (h *Heartbeat) SetupWithManager(mgr ctrl.Manager) error {
+	c, err := controller.New(heartbeatControllerName, mgr, controller.Options{Reconciler: h, NeedLeaderElection: ptr.To(false)})
+	if err != nil {
+		return err
+	}
+	if err := c.Watch(source.Channel(h.events, &handler.EnqueueRequestForObject{})); err != nil {
+		return err
+	}
+	return mgr.Add(h)
+}
+
+// NeedLeaderElection implements the manager.LeaderElectionRunnable interface; the heartbeat runs on all replicas.
+func (h *Heartbeat) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements the manager.Runnable interface.
+func (h *Heartbeat) Start(ctx context.Context) error {
+	// controllers only start processing once the cache has synced, which may take a while on large clusters
+	if !h.cache.WaitForCacheSync(ctx) {
+		return nil
+	}
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		h.probe(time.Now())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// send a probe event, unless the previous one is still queued
+func (h *Heartbeat) probe(now time.Time) {
+	h.pending.CompareAndSwap(0, now.UnixNano())
+	select {
+	case h.events <- event.GenericEvent{Object: &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: heartbeatControllerName}}}:
+	default:
+	}
+}
+
+// Reconcile implements the reconcile.Reconciler interface; it is called for the probes sent by the heartbeat.
+func (h *Heartbeat) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	h.pending.Store(0)
+	return reconcile.Result{}, nil
+}
+
+// Checker returns a checker which fails if a probe was not processed within the heartbeat timeout.
+func (h *Heartbeat) Checker() healthz.Checker {
+	return func(req *http.Request) error {
+		return h.check(time.Now())
+	}
+}
+
+func (h *Heartbeat) check(now time.Time) error {
+	pending := h.pending.Load()
+	if pending == 0 {
+		return nil
+	}
+	if age := now.Sub(time.Unix(0, pending)); age > heartbeatTimeout {
+		return fmt.Errorf("heartbeat not processed for %s", age.Round(time.Second))
+	}
+	return nil
+}
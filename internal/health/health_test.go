@@ -0,0 +1,62 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Test heartbeat", func() {
+	var h *Heartbeat
+
+	BeforeEach(func() {
+		h = NewHeartbeat(nil)
+	})
+
+	processProbe := func() {
+		EventuallyWithOffset(1, h.events).Should(Receive())
+		_, err := h.Reconcile(ctx, reconcile.Request{})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	}
+
+	It("should succeed before the first probe", func() {
+		Expect(h.check(time.Now().Add(time.Hour))).To(Succeed())
+	})
+
+	It("should succeed while probes are processed", func() {
+		now := time.Now()
+		for i := 0; i < 10; i++ {
+			now = now.Add(heartbeatInterval)
+			h.probe(now)
+			processProbe()
+			Expect(h.check(now.Add(heartbeatTimeout + time.Second))).To(Succeed())
+		}
+	})
+
+	It("should fail if a probe is not processed within the timeout", func() {
+		now := time.Now()
+		h.probe(now)
+		Expect(h.check(now.Add(heartbeatTimeout - time.Second))).To(Succeed())
+		Expect(h.check(now.Add(heartbeatTimeout + time.Second))).To(MatchError(ContainSubstring("heartbeat not processed")))
+	})
+
+	It("should measure the timeout from the oldest unprocessed probe, without blocking on queued probes", func() {
+		now := time.Now()
+		for i := 0; i < 10; i++ {
+			h.probe(now.Add(time.Duration(i) * heartbeatInterval))
+		}
+		Expect(h.events).To(HaveLen(1))
+		Expect(h.check(now.Add(heartbeatTimeout + time.Second))).To(HaveOccurred())
+
+		processProbe()
+		Expect(h.check(now.Add(heartbeatTimeout + time.Second))).To(Succeed())
+	})
+})
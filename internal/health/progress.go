@@ -0,0 +1,108 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// time after which a controller with pending work, but without progress, is considered as stalled;
+// larger than the heartbeat timeout, since single reloads may legitimately take a while (e.g. when calling reload endpoints)
+const progressTimeout = 2 * time.Minute
+
+// Progress tracks the work queues of controllers, recording per controller when an item was last dequeued.
+// Its checker fails if a controller has items ready for processing, but made no progress for some time, indicating that
+// the controller is stalled (e.g. because all of its workers are blocked), even if other controllers of the manager are still working.
+type Progress struct {
+	mutex  sync.Mutex
+	queues map[string]*progressQueue
+}
+
+// NewProgress creates an empty progress tracker.
+func NewProgress() *Progress {
+	return &Progress{queues: make(map[string]*progressQueue)}
+}
+
+// NewQueue creates a (priority) work queue for the given controller, whose progress is tracked;
+// meant to be used as NewQueue in the options of the tracked controllers.
+func (p *Progress) NewQueue(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	queue := priorityqueue.New(controllerName, func(o *priorityqueue.Opts[reconcile.Request]) {
+		o.Log = ctrl.Log.WithValues("controller", controllerName)
+		o.RateLimiter = rateLimiter
+	})
+	return p.track(controllerName, queue, time.Now())
+}
+
+func (p *Progress) track(controllerName string, queue priorityqueue.PriorityQueue[reconcile.Request], now time.Time) *progressQueue {
+	q := &progressQueue{PriorityQueue: queue}
+	q.progress.Store(now.UnixNano())
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.queues[controllerName] = q
+	return q
+}
+
+// Checker returns a checker which fails if a tracked controller has pending work, but did not make progress within the progress timeout.
+func (p *Progress) Checker() healthz.Checker {
+	return func(req *http.Request) error {
+		return p.check(time.Now())
+	}
+}
+
+func (p *Progress) check(now time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var stalled []string
+	for name, q := range p.queues {
+		if q.ShuttingDown() {
+			continue
+		}
+		// an empty queue counts as progress, such that the timeout is measured from the time work became pending
+		if q.Len() == 0 {
+			q.progress.Store(now.UnixNano())
+			continue
+		}
+		if age := now.Sub(time.Unix(0, q.progress.Load())); age > progressTimeout {
+			stalled = append(stalled, fmt.Sprintf("%s (%s)", name, age.Round(time.Second)))
+		}
+	}
+	if len(stalled) > 0 {
+		sort.Strings(stalled)
+		return fmt.Errorf("controllers with pending work did not make progress: %s", strings.Join(stalled, ", "))
+	}
+	return nil
+}
+
+// work queue recording the time an item was last dequeued
+type progressQueue struct {
+	priorityqueue.PriorityQueue[reconcile.Request]
+	progress atomic.Int64
+}
+
+func (q *progressQueue) Get() (reconcile.Request, bool) {
+	item, _, shutdown := q.GetWithPriority()
+	return item, shutdown
+}
+
+func (q *progressQueue) GetWithPriority() (reconcile.Request, int, bool) {
+	item, priority, shutdown := q.PriorityQueue.GetWithPriority()
+	if !shutdown {
+		q.progress.Store(time.Now().UnixNano())
+	}
+	return item, priority, shutdown
+}
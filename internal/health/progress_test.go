@@ -0,0 +1,71 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Test progress", func() {
+	var p *Progress
+	var q *progressQueue
+	var now time.Time
+
+	request := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "test", Name: name}}
+	}
+
+	BeforeEach(func() {
+		p = NewProgress()
+		now = time.Now()
+		q = p.track("test-handler", priorityqueue.New[reconcile.Request]("test-handler"), now)
+		DeferCleanup(func() {
+			if !q.ShuttingDown() {
+				q.ShutDown()
+			}
+		})
+	})
+
+	It("should succeed while the queue is empty", func() {
+		Expect(p.check(now.Add(time.Hour))).To(Succeed())
+	})
+
+	It("should succeed while pending requests are dequeued", func() {
+		q.Add(request("a"))
+		Expect(p.check(now.Add(progressTimeout - time.Second))).To(Succeed())
+
+		item, shutdown := q.Get()
+		Expect(shutdown).To(BeFalse())
+		Expect(item).To(Equal(request("a")))
+		q.Add(request("b"))
+		Expect(p.check(time.Now().Add(progressTimeout - time.Second))).To(Succeed())
+	})
+
+	It("should fail if pending requests are not dequeued within the timeout", func() {
+		q.Add(request("a"))
+		Expect(p.check(now.Add(progressTimeout + time.Second))).To(MatchError(ContainSubstring("test-handler")))
+	})
+
+	It("should measure the timeout from the time the queue was last seen empty", func() {
+		Expect(p.check(now.Add(time.Hour))).To(Succeed())
+		q.Add(request("a"))
+		Expect(p.check(now.Add(time.Hour + progressTimeout - time.Second))).To(Succeed())
+		Expect(p.check(now.Add(time.Hour + progressTimeout + time.Second))).To(HaveOccurred())
+	})
+
+	It("should ignore controllers which were shut down", func() {
+		q.Add(request("a"))
+		q.ShutDown()
+		Expect(p.check(now.Add(progressTimeout + time.Second))).To(Succeed())
+	})
+})
@@ -0,0 +1,32 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var ctx context.Context
+var cancel context.CancelFunc
+
+func TestHealth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Health Suite")
+}
+
+var _ = BeforeSuite(func() {
+	By("setting up context")
+	ctx, cancel = context.WithCancel(context.TODO())
+})
+
+var _ = AfterSuite(func() {
+	By("cancelling context")
+	cancel()
+})
@@ -0,0 +1,280 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultRegistry = "registry-1.docker.io"
+)
+
+// media types of manifests and manifest lists/indexes; the digest of the list is returned if the image is multi-platform
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Image is a parsed image reference.
+type Image struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImage parses an image reference of the form [registry/]repository[:tag][@digest]; references without registry
+// are resolved against docker hub (where single-component repositories are prefixed with library/), and the tag defaults to latest.
+func ParseImage(reference string) (*Image, error) {
+	image := &Image{}
+	name := reference
+	if i := strings.Index(name, "@"); i >= 0 {
+		image.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i+1:], "/") {
+		image.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.Index(name, "/"); i >= 0 && (strings.ContainsAny(name[:i], ".:") || name[:i] == "localhost") {
+		image.Registry = name[:i]
+		name = name[i+1:]
+	}
+	if name == "" || name != strings.ToLower(name) {
+		return nil, fmt.Errorf("invalid image reference: %s", reference)
+	}
+	if image.Registry == "" || image.Registry == "docker.io" || image.Registry == "index.docker.io" {
+		image.Registry = defaultRegistry
+		if !strings.Contains(name, "/") {
+			name = "library/" + name
+		}
+	}
+	image.Repository = name
+	if image.Tag == "" && image.Digest == "" {
+		image.Tag = "latest"
+	}
+	return image, nil
+}
+
+// Credentials are the credentials for a registry, as contained in image pull secrets.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Keychain maps registries (as in Image.Registry) to credentials.
+type Keychain map[string]Credentials
+
+// AddDockerConfig adds the credentials contained in the given docker config (as found in image pull secrets, either in the
+// .dockerconfigjson or in the legacy .dockercfg format) to the keychain; registries already contained in the keychain are left unchanged.
+func (k Keychain) AddDockerConfig(data []byte) error {
+	type entry struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+	var config struct {
+		Auths map[string]entry `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	if config.Auths == nil {
+		// legacy format, without the auths wrapper
+		if err := json.Unmarshal(data, &config.Auths); err != nil {
+			return err
+		}
+	}
+	for server, entry := range config.Auths {
+		if entry.Auth != "" {
+			auth, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return fmt.Errorf("invalid auth for registry %s: %w", server, err)
+			}
+			username, password, ok := strings.Cut(string(auth), ":")
+			if !ok {
+				return fmt.Errorf("invalid auth for registry %s: expected username:password", server)
+			}
+			entry.Username, entry.Password = username, password
+		}
+		registry := normalizeRegistry(server)
+		if _, ok := k[registry]; !ok && registry != "" {
+			k[registry] = Credentials{Username: entry.Username, Password: entry.Password}
+		}
+	}
+	return nil
+}
+
+// normalize a registry key of a docker config (which may contain a scheme and a path, as in https://index.docker.io/v1/)
+func normalizeRegistry(server string) string {
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	server, _, _ = strings.Cut(server, "/")
+	if server == "docker.io" || server == "index.docker.io" {
+		return defaultRegistry
+	}
+	return server
+}
+
+// Resolver resolves image tags to digests by querying the registry (anonymously, or with the credentials of a keychain;
+// using bearer tokens if requested by the registry).
+type Resolver struct {
+	client *http.Client
+}
+
+// NewResolver creates a resolver using the given http client.
+func NewResolver(client *http.Client) *Resolver {
+	return &Resolver{client: client}
+}
+
+// ResolveDigest returns the current digest of the given image reference; if the reference contains a digest, that digest is returned.
+// The registry is queried with the credentials found in the given keychain (which may be nil) for the image's registry, if any, and anonymously otherwise.
+func (r *Resolver) ResolveDigest(ctx context.Context, reference string, keychain Keychain) (string, error) {
+	image, err := ParseImage(reference)
+	if err != nil {
+		return "", err
+	}
+	if image.Digest != "" {
+		return image.Digest, nil
+	}
+	var credentials *Credentials
+	if c, ok := keychain[image.Registry]; ok {
+		credentials = &c
+	}
+
+	manifestURL := "https://" + image.Registry + "/v2/" + image.Repository + "/manifests/" + image.Tag
+	resp, err := r.headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		var authorization string
+		if scheme, _, _ := strings.Cut(challenge, " "); strings.EqualFold(scheme, "Basic") && credentials != nil {
+			authorization = "Basic " + basicAuth(credentials)
+		} else {
+			token, err := r.fetchToken(ctx, challenge, image.Repository, credentials)
+			if err != nil {
+				return "", err
+			}
+			authorization = "Bearer " + token
+		}
+		if resp, err = r.headManifest(ctx, manifestURL, authorization); err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error resolving digest of image %s: unexpected status %d", reference, resp.StatusCode)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("error resolving digest of image %s: registry did not return a digest", reference)
+	}
+	return digest, nil
+}
+
+func (r *Resolver) headManifest(ctx context.Context, manifestURL string, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// fetch a pull token, as requested by the given WWW-Authenticate header; the token is requested anonymously if no credentials are given
+func (r *Resolver) fetchToken(ctx context.Context, challenge string, repository string, credentials *Credentials) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported authentication challenge: %s", challenge)
+	}
+	values := parseChallengeParams(params)
+	if values["realm"] == "" {
+		return "", fmt.Errorf("invalid authentication challenge (missing realm): %s", challenge)
+	}
+	tokenURL, err := url.Parse(values["realm"])
+	if err != nil {
+		return "", err
+	}
+	query := tokenURL.Query()
+	if values["service"] != "" {
+		query.Set("service", values["service"])
+	}
+	scope := values["scope"]
+	if scope == "" {
+		scope = "repository:" + repository + ":pull"
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if credentials != nil {
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error fetching registry token: unexpected status %d", resp.StatusCode)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("error fetching registry token: empty token")
+}
+
+func basicAuth(credentials *Credentials) string {
+	return base64.StdEncoding.EncodeToString([]byte(credentials.Username + ":" + credentials.Password))
+}
+
+// parse the comma-separated key="value" parameters of an authentication challenge
+func parseChallengeParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		var key, value string
+		key, s, _ = strings.Cut(s, "=")
+		key = strings.TrimSpace(key)
+		if strings.HasPrefix(s, "\"") {
+			value, s, _ = strings.Cut(s[1:], "\"")
+			_, s, _ = strings.Cut(s, ",")
+		} else {
+			value, s, _ = strings.Cut(s, ",")
+		}
+		params[strings.ToLower(key)] = strings.TrimSpace(value)
+	}
+	return params
+}
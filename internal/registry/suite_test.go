@@ -0,0 +1,154 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sap/pod-reloader/internal/registry"
+)
+
+func TestRegistry(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Registry Suite")
+}
+
+var _ = Describe("Test image reference parsing", func() {
+	It("should apply docker hub defaults", func() {
+		Expect(registry.ParseImage("nginx")).To(Equal(&registry.Image{Registry: "registry-1.docker.io", Repository: "library/nginx", Tag: "latest"}))
+		Expect(registry.ParseImage("bitnami/nginx:1.25")).To(Equal(&registry.Image{Registry: "registry-1.docker.io", Repository: "bitnami/nginx", Tag: "1.25"}))
+	})
+
+	It("should parse registry, tag and digest", func() {
+		Expect(registry.ParseImage("localhost:5000/tools/cli:stable")).To(Equal(&registry.Image{Registry: "localhost:5000", Repository: "tools/cli", Tag: "stable"}))
+		Expect(registry.ParseImage("ghcr.io/sap/pod-reloader@sha256:abc")).To(Equal(&registry.Image{Registry: "ghcr.io", Repository: "sap/pod-reloader", Digest: "sha256:abc"}))
+		_, err := registry.ParseImage("Invalid/Image")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Test keychains", func() {
+	It("should parse docker configs in both formats", func() {
+		keychain := registry.Keychain{}
+		Expect(keychain.AddDockerConfig([]byte(`{"auths":{"https://index.docker.io/v1/":{"username":"hub","password":"secret"}}}`))).To(Succeed())
+		Expect(keychain.AddDockerConfig([]byte(`{"ghcr.io":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("gh:token")) + `"}}`))).To(Succeed())
+		Expect(keychain).To(Equal(registry.Keychain{
+			"registry-1.docker.io": {Username: "hub", Password: "secret"},
+			"ghcr.io":              {Username: "gh", Password: "token"},
+		}))
+	})
+
+	It("should keep credentials added first", func() {
+		keychain := registry.Keychain{}
+		Expect(keychain.AddDockerConfig([]byte(`{"auths":{"ghcr.io":{"username":"first","password":"1"}}}`))).To(Succeed())
+		Expect(keychain.AddDockerConfig([]byte(`{"auths":{"ghcr.io":{"username":"second","password":"2"}}}`))).To(Succeed())
+		Expect(keychain["ghcr.io"].Username).To(Equal("first"))
+	})
+
+	It("should reject invalid docker configs", func() {
+		Expect(registry.Keychain{}.AddDockerConfig([]byte(`invalid`))).NotTo(Succeed())
+		Expect(registry.Keychain{}.AddDockerConfig([]byte(`{"auths":{"ghcr.io":{"auth":"invalid"}}}`))).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("Test digest resolution", func() {
+	var server *httptest.Server
+	var digest string
+
+	BeforeEach(func() {
+		digest = "sha256:1111"
+		mux := http.NewServeMux()
+		mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("scope") != "repository:tools/cli:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"token":"secret"}`))
+		})
+		mux.HandleFunc("/private-token", func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"access_token":"private"}`))
+		})
+		mux.HandleFunc("/v2/tools/private/manifests/stable", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer private" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/private-token",service="registry"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+		})
+		mux.HandleFunc("/v2/tools/basic/manifests/stable", func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+		})
+		mux.HandleFunc("/v2/tools/cli/manifests/stable", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:tools/cli:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+		})
+		server = httptest.NewTLSServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should resolve tags to digests, and detect re-pushed tags", func() {
+		resolver := registry.NewResolver(server.Client())
+		image := strings.TrimPrefix(server.URL, "https://") + "/tools/cli:stable"
+
+		Expect(resolver.ResolveDigest(context.TODO(), image, nil)).To(Equal("sha256:1111"))
+		digest = "sha256:2222"
+		Expect(resolver.ResolveDigest(context.TODO(), image, nil)).To(Equal("sha256:2222"))
+	})
+
+	It("should use credentials from the keychain for private repositories", func() {
+		resolver := registry.NewResolver(server.Client())
+		host := strings.TrimPrefix(server.URL, "https://")
+		keychain := registry.Keychain{}
+		Expect(keychain.AddDockerConfig([]byte(`{"auths":{"https://` + host + `/v1/":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("user:pass")) + `"}}}`))).To(Succeed())
+
+		_, err := resolver.ResolveDigest(context.TODO(), host+"/tools/private:stable", nil)
+		Expect(err).To(HaveOccurred())
+		Expect(resolver.ResolveDigest(context.TODO(), host+"/tools/private:stable", keychain)).To(Equal("sha256:1111"))
+		_, err = resolver.ResolveDigest(context.TODO(), host+"/tools/basic:stable", nil)
+		Expect(err).To(HaveOccurred())
+		Expect(resolver.ResolveDigest(context.TODO(), host+"/tools/basic:stable", keychain)).To(Equal("sha256:1111"))
+	})
+
+	It("should fail for unknown images", func() {
+		resolver := registry.NewResolver(server.Client())
+		_, err := resolver.ResolveDigest(context.TODO(), strings.TrimPrefix(server.URL, "https://")+"/tools/other:stable", nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return digests of pinned images without querying the registry", func() {
+		resolver := registry.NewResolver(http.DefaultClient)
+		Expect(resolver.ResolveDigest(context.TODO(), "example.invalid/tools/cli@sha256:3333", nil)).To(Equal("sha256:3333"))
+	})
+})
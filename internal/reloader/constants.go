@@ -6,7 +6,51 @@ SPDX-License-Identifier: Apache-2.0
 package reloader
 
 const (
-	AnnotationConfigHash = "pod-reloader.cs.sap.com/config-hash"
-	AnnotationConfigMaps = "pod-reloader.cs.sap.com/configmaps"
-	AnnotationSecrets    = "pod-reloader.cs.sap.com/secrets"
+	AnnotationConfigHash                   = "pod-reloader.cs.sap.com/config-hash"
+	AnnotationConfigMaps                   = "pod-reloader.cs.sap.com/configmaps"
+	AnnotationSecrets                      = "pod-reloader.cs.sap.com/secrets"
+	AnnotationSecretProviderClasses        = "pod-reloader.cs.sap.com/secretproviderclasses"
+	AnnotationResources                    = "pod-reloader.cs.sap.com/resources"
+	AnnotationTrackImageDigests            = "pod-reloader.cs.sap.com/track-image-digests"
+	AnnotationImageDigests                 = "pod-reloader.cs.sap.com/image-digests"
+	AnnotationImageDigestRevision          = "pod-reloader.cs.sap.com/image-digest-revision"
+	AnnotationIncludeImplicitSecrets       = "pod-reloader.cs.sap.com/include-implicit-secrets"
+	AnnotationMaintenanceWindows           = "pod-reloader.cs.sap.com/maintenance-windows"
+	AnnotationMinReloadInterval            = "pod-reloader.cs.sap.com/min-reload-interval"
+	AnnotationWaitForRollout               = "pod-reloader.cs.sap.com/wait-for-rollout"
+	AnnotationRollback                     = "pod-reloader.cs.sap.com/rollback"
+	AnnotationRollbackTimeout              = "pod-reloader.cs.sap.com/rollback-timeout"
+	AnnotationImmutableConfig              = "pod-reloader.cs.sap.com/immutable-config"
+	AnnotationSnapshotOf                   = "pod-reloader.cs.sap.com/snapshot-of"
+	AnnotationStrategy                     = "pod-reloader.cs.sap.com/strategy"
+	AnnotationAppliedConfigHash            = "pod-reloader.cs.sap.com/applied-config-hash"
+	AnnotationSignal                       = "pod-reloader.cs.sap.com/signal"
+	AnnotationSignalContainers             = "pod-reloader.cs.sap.com/signal-containers"
+	AnnotationReloadScheme                 = "pod-reloader.cs.sap.com/reload-scheme"
+	AnnotationReloadPort                   = "pod-reloader.cs.sap.com/reload-port"
+	AnnotationReloadPath                   = "pod-reloader.cs.sap.com/reload-path"
+	AnnotationReloadMethod                 = "pod-reloader.cs.sap.com/reload-method"
+	AnnotationEvictionInterval             = "pod-reloader.cs.sap.com/eviction-interval"
+	AnnotationHashMode                     = "pod-reloader.cs.sap.com/hash-mode"
+	AnnotationReloadPriority               = "pod-reloader.cs.sap.com/reload-priority"
+	AnnotationAppliedContainerConfigHashes = "pod-reloader.cs.sap.com/applied-container-config-hashes"
+	AnnotationRolloutGeneration            = "pod-reloader.cs.sap.com/rollout-generation"
+)
+
+const (
+	StrategyRestart           = "restart"
+	StrategySignal            = "signal"
+	StrategyHTTP              = "http"
+	StrategyRestartContainers = "restart-containers"
+)
+
+const (
+	HashModeVersion = "version"
+	HashModeContent = "content"
+)
+
+const (
+	LabelSnapshot = "pod-reloader.cs.sap.com/snapshot"
+	LabelCanary   = "pod-reloader.cs.sap.com/canary"
+	LabelShard    = "pod-reloader.cs.sap.com/shard"
 )
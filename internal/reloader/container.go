@@ -0,0 +1,140 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ContainerReferences determines, for each container (including init containers) of the given pod spec, the names of the config maps
+// and secrets it consumes, through volume mounts, environment variables or environment sources; the returned map is indexed by
+// container name and kind (KindConfigMap, KindSecret).
+func ContainerReferences(podSpec *corev1.PodSpec) map[string]map[string][]string {
+	volumes := make(map[string]*corev1.PodSpec, len(podSpec.Volumes))
+	for i := range podSpec.Volumes {
+		volumes[podSpec.Volumes[i].Name] = &corev1.PodSpec{Volumes: []corev1.Volume{podSpec.Volumes[i]}}
+	}
+
+	references := make(map[string]map[string][]string)
+	add := func(containerName string, kind string, name string) {
+		if references[containerName] == nil {
+			references[containerName] = make(map[string][]string)
+		}
+		for _, n := range references[containerName][kind] {
+			if n == name {
+				return
+			}
+		}
+		references[containerName][kind] = append(references[containerName][kind], name)
+	}
+	visitContainer := func(container *corev1.Container) {
+		if references[container.Name] == nil {
+			references[container.Name] = make(map[string][]string)
+		}
+		for _, volumeMount := range container.VolumeMounts {
+			if volume, ok := volumes[volumeMount.Name]; ok {
+				VisitReferences(volume, func(kind string, name *string) { add(container.Name, kind, *name) })
+			}
+		}
+		VisitReferences(&corev1.PodSpec{Containers: []corev1.Container{{Env: container.Env, EnvFrom: container.EnvFrom}}}, func(kind string, name *string) {
+			add(container.Name, kind, *name)
+		})
+	}
+	for i := range podSpec.InitContainers {
+		visitContainer(&podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		visitContainer(&podSpec.Containers[i])
+	}
+	return references
+}
+
+// GenerateContainerHashes computes a hash per container (including init containers) of the given pod spec, taking into account
+// only those of the given config maps and secrets which are consumed by the respective container. Config maps and secrets which are
+// not consumed by any container at all (for example because they are read through the API) are considered to affect all containers.
+func GenerateContainerHashes(ctx context.Context, client ctrlclient.Reader, namespace string, podSpec *corev1.PodSpec, configMapNames []string, secretNames []string) (map[string]string, error) {
+	references := ContainerReferences(podSpec)
+
+	consumed := map[string]map[string]bool{KindConfigMap: {}, KindSecret: {}}
+	for _, containerReferences := range references {
+		for kind, names := range containerReferences {
+			for _, name := range names {
+				consumed[kind][name] = true
+			}
+		}
+	}
+
+	filter := func(containerName string, kind string, names []string) []string {
+		var result []string
+		for _, name := range names {
+			if !consumed[kind][name] || slices.Contains(references[containerName][kind], name) {
+				result = append(result, name)
+			}
+		}
+		return result
+	}
+
+	hashes := make(map[string]string, len(references))
+	for containerName := range references {
+		hash, err := GenerateHash(ctx, client, namespace, filter(containerName, KindConfigMap, configMapNames), filter(containerName, KindSecret, secretNames))
+		if err != nil {
+			return nil, err
+		}
+		hashes[containerName] = hash
+	}
+	return hashes, nil
+}
+
+// GenerateContainerHashesForObject computes per-container hashes for the given workload object (see GenerateContainerHashes),
+// with the referenced config maps and secrets taken from the object's annotations. References which cannot be attributed to individual
+// containers (secret provider classes, arbitrary resources, implicit secrets and image digests) are folded into the hashes of all containers,
+// in the same way as in GenerateHashForObject.
+func GenerateContainerHashesForObject(ctx context.Context, client ctrlclient.Reader, object ctrlclient.Object) (map[string]string, error) {
+	podSpec := PodSpec(object)
+	if podSpec == nil {
+		return map[string]string{}, nil
+	}
+	annotations := object.GetAnnotations()
+	hashes, err := GenerateContainerHashes(ctx, client, object.GetNamespace(), podSpec, SplitNames(annotations[AnnotationConfigMaps]), SplitNames(annotations[AnnotationSecrets]))
+	if err != nil {
+		return nil, err
+	}
+	podHashes, err := generatePodHashes(ctx, client, object.GetNamespace(), annotations, podSpec, hashMode(annotations))
+	if err != nil {
+		return nil, err
+	}
+	for containerName, hash := range hashes {
+		hashes[containerName] = combineHashes(hash, podHashes)
+	}
+	return hashes, nil
+}
+
+// FormatContainerHashes serializes per-container hashes, as returned by GenerateContainerHashes, for storage in an annotation.
+func FormatContainerHashes(hashes map[string]string) string {
+	// json.Marshal sorts map keys, so the result is deterministic
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// ParseContainerHashes deserializes per-container hashes, as formatted by FormatContainerHashes.
+func ParseContainerHashes(s string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	if s == "" {
+		return hashes, nil
+	}
+	if err := json.Unmarshal([]byte(s), &hashes); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
@@ -0,0 +1,127 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test per-container hash computation", func() {
+	var cli ctrlclient.Client
+	var namespace string
+	var podSpec *corev1.PodSpec
+
+	BeforeEach(func() {
+		namespace = "test"
+
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		cli = fakeclient.NewClientBuilder().WithScheme(scheme).Build()
+
+		for _, name := range []string{"app", "sidecar", "other"} {
+			Expect(cli.Create(ctx, buildConfigMap(namespace, name, "key", "value"))).To(Succeed())
+		}
+		Expect(cli.Create(ctx, buildSecret(namespace, "shared", "key", "value"))).To(Succeed())
+
+		podSpec = &corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:         "app",
+					VolumeMounts: []corev1.VolumeMount{{Name: "config", MountPath: "/config"}},
+					EnvFrom:      []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "shared"}}}},
+				},
+				{
+					Name: "sidecar",
+					Env: []corev1.EnvVar{{Name: "KEY", ValueFrom: &corev1.EnvVarSource{
+						ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "sidecar"}, Key: "key"},
+					}}},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app"}}}},
+			},
+		}
+	})
+
+	It("should determine the references consumed by each container", func() {
+		references := reloader.ContainerReferences(podSpec)
+		Expect(references).To(HaveLen(2))
+		Expect(references["app"][reloader.KindConfigMap]).To(ConsistOf("app"))
+		Expect(references["app"][reloader.KindSecret]).To(ConsistOf("shared"))
+		Expect(references["sidecar"][reloader.KindConfigMap]).To(ConsistOf("sidecar"))
+		Expect(references["sidecar"][reloader.KindSecret]).To(BeEmpty())
+	})
+
+	It("should only consider consumed references, and unconsumed references for all containers", func() {
+		hashes, err := reloader.GenerateContainerHashes(ctx, cli, namespace, podSpec, []string{"app", "sidecar", "other"}, []string{"shared"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hashes).To(HaveLen(2))
+
+		appHash, err := reloader.GenerateHash(ctx, cli, namespace, []string{"app", "other"}, []string{"shared"})
+		Expect(err).NotTo(HaveOccurred())
+		sidecarHash, err := reloader.GenerateHash(ctx, cli, namespace, []string{"sidecar", "other"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hashes["app"]).To(Equal(appHash))
+		Expect(hashes["sidecar"]).To(Equal(sidecarHash))
+	})
+
+	It("should fold references affecting the whole pod into the hashes of all containers", func() {
+		Expect(cli.Create(ctx, buildConfigMap(namespace, "feature", "key", "value"))).To(Succeed())
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "test",
+				Annotations: map[string]string{
+					reloader.AnnotationConfigMaps: "app,sidecar,other",
+					reloader.AnnotationSecrets:    "shared",
+				},
+			},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: *podSpec}},
+		}
+		plainHashes, err := reloader.GenerateContainerHashesForObject(ctx, cli, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		expectedHashes, err := reloader.GenerateContainerHashes(ctx, cli, namespace, podSpec, []string{"app", "sidecar", "other"}, []string{"shared"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plainHashes).To(Equal(expectedHashes))
+
+		deployment.Annotations[reloader.AnnotationResources] = "v1/ConfigMap:feature"
+		hashes, err := reloader.GenerateContainerHashesForObject(ctx, cli, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hashes).To(HaveLen(2))
+		Expect(hashes["app"]).NotTo(Equal(plainHashes["app"]))
+		Expect(hashes["sidecar"]).NotTo(Equal(plainHashes["sidecar"]))
+
+		configMap := &corev1.ConfigMap{}
+		Expect(cli.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: "feature"}, configMap)).To(Succeed())
+		configMap.Data["key"] = "changed"
+		Expect(cli.Update(ctx, configMap)).To(Succeed())
+		changedHashes, err := reloader.GenerateContainerHashesForObject(ctx, cli, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changedHashes["app"]).NotTo(Equal(hashes["app"]))
+		Expect(changedHashes["sidecar"]).NotTo(Equal(hashes["sidecar"]))
+	})
+
+	It("should round-trip formatted hashes", func() {
+		hashes := map[string]string{"b": "2", "a": "1"}
+		s := reloader.FormatContainerHashes(hashes)
+		Expect(s).To(Equal(`{"a":"1","b":"2"}`))
+		parsed, err := reloader.ParseContainerHashes(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(hashes))
+	})
+})
@@ -0,0 +1,43 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ConfigMapContentHash returns a digest of the data of the given config map.
+func ConfigMapContentHash(configMap *corev1.ConfigMap) string {
+	return contentHash(struct {
+		Data       map[string]string `json:"data,omitempty"`
+		BinaryData map[string][]byte `json:"binaryData,omitempty"`
+	}{
+		Data:       configMap.Data,
+		BinaryData: configMap.BinaryData,
+	})
+}
+
+// SecretContentHash returns a digest of the type and data of the given secret.
+func SecretContentHash(secret *corev1.Secret) string {
+	return contentHash(struct {
+		Type corev1.SecretType `json:"type,omitempty"`
+		Data map[string][]byte `json:"data,omitempty"`
+	}{
+		Type: secret.Type,
+		Data: secret.Data,
+	})
+}
+
+func contentHash(v any) string {
+	// note: maps are marshalled with sorted keys, so the result is deterministic
+	raw, err := json.Marshal(v)
+	if err != nil {
+		panic("this cannot happen")
+	}
+	return sha256sum(string(raw))
+}
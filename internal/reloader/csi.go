@@ -0,0 +1,86 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SecretProviderClassGroupVersionKind is the kind of the secret provider classes of the secrets store csi driver.
+var SecretProviderClassGroupVersionKind = schema.GroupVersionKind{
+	Group:   "secrets-store.csi.x-k8s.io",
+	Version: "v1",
+	Kind:    "SecretProviderClass",
+}
+
+// SecretProviderClassPodStatusGroupVersionKind is the kind of the objects through which the secrets store csi driver
+// reports the versions of the objects mounted into a pod.
+var SecretProviderClassPodStatusGroupVersionKind = schema.GroupVersionKind{
+	Group:   "secrets-store.csi.x-k8s.io",
+	Version: "v1",
+	Kind:    "SecretProviderClassPodStatus",
+}
+
+// SecretProviderClassOf returns the name of the secret provider class the given SecretProviderClassPodStatus belongs to.
+func SecretProviderClassOf(status *unstructured.Unstructured) string {
+	name, _, _ := unstructured.NestedString(status.Object, "status", "secretProviderClassName")
+	return name
+}
+
+// GenerateSecretProviderClassHash calculates a hash from the object versions currently provided by the given secret provider classes;
+// for each secret provider class, the versions reported by the most recently created (mounted) pod status in the namespace are used,
+// since pods are mounted with the latest versions, and existing pods are rotated to these versions sooner or later.
+// Note that reading the pod status objects is uncached, unless the client is configured to cache unstructured objects.
+func GenerateSecretProviderClassHash(ctx context.Context, client ctrlclient.Reader, namespace string, secretProviderClassNames []string) (string, error) {
+	statusList := &unstructured.UnstructuredList{}
+	statusList.SetGroupVersionKind(SecretProviderClassPodStatusGroupVersionKind.GroupVersion().WithKind(SecretProviderClassPodStatusGroupVersionKind.Kind + "List"))
+	if err := client.List(ctx, statusList, ctrlclient.InNamespace(namespace)); err != nil && !meta.IsNoMatchError(err) {
+		return "", err
+	}
+
+	latest := make(map[string]*unstructured.Unstructured)
+	for i := range statusList.Items {
+		status := &statusList.Items[i]
+		if mounted, _, _ := unstructured.NestedBool(status.Object, "status", "mounted"); !mounted {
+			continue
+		}
+		name := SecretProviderClassOf(status)
+		if other, ok := latest[name]; ok {
+			if t, u := status.GetCreationTimestamp(), other.GetCreationTimestamp(); t.Before(&u) || (t.Equal(&u) && status.GetName() > other.GetName()) {
+				continue
+			}
+		}
+		latest[name] = status
+	}
+
+	s := ""
+	for _, secretProviderClassName := range secretProviderClassNames {
+		s += "secretproviderclass/" + namespace + "/" + secretProviderClassName + "/"
+		if status, ok := latest[secretProviderClassName]; ok {
+			objects, _, _ := unstructured.NestedSlice(status.Object, "status", "objects")
+			versions := make([]string, 0, len(objects))
+			for _, object := range objects {
+				if object, ok := object.(map[string]any); ok {
+					id, _, _ := unstructured.NestedString(object, "id")
+					version, _, _ := unstructured.NestedString(object, "version")
+					versions = append(versions, id+"="+version)
+				}
+			}
+			sort.Strings(versions)
+			for _, version := range versions {
+				s += version + ";"
+			}
+		}
+		s += "\n"
+	}
+	return sha256sum(s), nil
+}
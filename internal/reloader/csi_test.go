@@ -0,0 +1,78 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test secret provider class hash computation", func() {
+	var scheme *runtime.Scheme
+
+	newStatus := func(name string, created time.Time, mounted bool, versions map[string]string) *unstructured.Unstructured {
+		var objects []any
+		for id, version := range versions {
+			objects = append(objects, map[string]any{"id": id, "version": version})
+		}
+		status := &unstructured.Unstructured{Object: map[string]any{
+			"status": map[string]any{
+				"secretProviderClassName": "vault",
+				"mounted":                 mounted,
+				"objects":                 objects,
+			},
+		}}
+		status.SetGroupVersionKind(reloader.SecretProviderClassPodStatusGroupVersionKind)
+		status.SetNamespace("test")
+		status.SetName(name)
+		status.SetCreationTimestamp(metav1.NewTime(created))
+		return status
+	}
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	})
+
+	It("should use the versions of the most recently created mounted pod status", func() {
+		now := time.Now().Truncate(time.Second)
+		cli := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newStatus("pod1-test-vault", now.Add(-2*time.Hour), true, map[string]string{"secret/a": "1"}),
+			newStatus("pod2-test-vault", now.Add(-1*time.Hour), true, map[string]string{"secret/a": "2"}),
+			newStatus("pod3-test-vault", now, false, nil),
+		).Build()
+		hash, err := reloader.GenerateSecretProviderClassHash(ctx, cli, "test", []string{"vault"})
+		Expect(err).NotTo(HaveOccurred())
+
+		cli = fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newStatus("pod2-test-vault", now.Add(-1*time.Hour), true, map[string]string{"secret/a": "2"}),
+			newStatus("pod4-test-vault", now, true, map[string]string{"secret/a": "2"}),
+		).Build()
+		Expect(reloader.GenerateSecretProviderClassHash(ctx, cli, "test", []string{"vault"})).To(Equal(hash))
+
+		cli = fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newStatus("pod2-test-vault", now.Add(-1*time.Hour), true, map[string]string{"secret/a": "3"}),
+		).Build()
+		Expect(reloader.GenerateSecretProviderClassHash(ctx, cli, "test", []string{"vault"})).NotTo(Equal(hash))
+	})
+
+	It("should tolerate missing pod statuses", func() {
+		cli := fakeclient.NewClientBuilder().WithScheme(scheme).Build()
+		_, err := reloader.GenerateSecretProviderClassHash(ctx, cli, "test", []string{"vault"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
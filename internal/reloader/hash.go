@@ -9,21 +9,36 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"strings"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func GenerateHash(ctx context.Context, client ctrlclient.Client, namespace string, configMapNames []string, secretNames []string) (string, error) {
+// GenerateHash calculates a hash over the given config maps and secrets, derived from their uid and resource version
+// (see GenerateHashWithMode).
+func GenerateHash(ctx context.Context, client ctrlclient.Reader, namespace string, configMapNames []string, secretNames []string) (string, error) {
+	return GenerateHashWithMode(ctx, client, namespace, HashModeVersion, configMapNames, secretNames)
+}
+
+// GenerateHashWithMode calculates a hash over the given config maps and secrets; with HashModeVersion, the hash is derived from the
+// uid and resource version of the referenced objects, with HashModeContent, it is derived from their content (as a consequence,
+// content hashes can be calculated offline, e.g. from manifests). Missing objects contribute to the hash as well.
+func GenerateHashWithMode(ctx context.Context, client ctrlclient.Reader, namespace string, mode string, configMapNames []string, secretNames []string) (string, error) {
+	if mode != HashModeVersion && mode != HashModeContent {
+		return "", fmt.Errorf("invalid hash mode: %s", mode)
+	}
 	s := ""
 	for _, configMapName := range configMapNames {
 		s += "configmap/" + namespace + "/" + configMapName + "/"
 		configMap := corev1.ConfigMap{}
 		err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: configMapName}, &configMap)
-		if err == nil {
+		if err == nil && mode == HashModeContent {
+			s += ConfigMapContentHash(&configMap) + "\n"
+		} else if err == nil {
 			s += string(configMap.UID) + "." + configMap.ResourceVersion + "\n"
 		} else if errors.IsNotFound(err) {
 			s += "\n"
@@ -35,7 +50,9 @@ func GenerateHash(ctx context.Context, client ctrlclient.Client, namespace strin
 		s += "secret/" + namespace + "/" + secretName + "/"
 		secret := corev1.Secret{}
 		err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: secretName}, &secret)
-		if err == nil {
+		if err == nil && mode == HashModeContent {
+			s += SecretContentHash(&secret) + "\n"
+		} else if err == nil {
 			s += string(secret.UID) + "." + secret.ResourceVersion + "\n"
 		} else if errors.IsNotFound(err) {
 			s += "\n"
@@ -46,22 +63,87 @@ func GenerateHash(ctx context.Context, client ctrlclient.Client, namespace strin
 	return sha256sum(s), nil
 }
 
-func GenerateHashForObject(ctx context.Context, client ctrlclient.Client, object metav1.Object) (string, error) {
-	var (
-		configMapNames []string
-		secretNames    []string
-	)
-
+// GenerateHashForObject calculates the configuration hash for the given object, according to the config maps and secrets referenced
+// in its annotations; the hash mode annotation is passed to GenerateHashWithMode. If secret provider classes
+// or arbitrary resources are referenced, the result is combined with GenerateSecretProviderClassHash or GenerateResourceHash, respectively;
+// if implicit secrets are included, or image digests are tracked, the implicit secrets' hash and the image digest revision are combined as well.
+func GenerateHashForObject(ctx context.Context, client ctrlclient.Reader, object metav1.Object) (string, error) {
 	annotations := object.GetAnnotations()
+	configMapNames := SplitNames(annotations[AnnotationConfigMaps])
+	secretNames := SplitNames(annotations[AnnotationSecrets])
+
+	mode := hashMode(annotations)
+	hash, err := GenerateHashWithMode(ctx, client, object.GetNamespace(), mode, configMapNames, secretNames)
+	if err != nil {
+		return "", err
+	}
+
+	var podSpec *corev1.PodSpec
+	if runtimeObject, ok := object.(runtime.Object); ok {
+		podSpec = PodSpec(runtimeObject)
+	}
+	podHashes, err := generatePodHashes(ctx, client, object.GetNamespace(), annotations, podSpec, mode)
+	if err != nil {
+		return "", err
+	}
+	return combineHashes(hash, podHashes), nil
+}
+
+// return the hash mode requested by the given annotations
+func hashMode(annotations map[string]string) string {
+	if mode := annotations[AnnotationHashMode]; mode != "" {
+		return mode
+	}
+	return HashModeVersion
+}
 
-	if annotations[AnnotationConfigMaps] != "" {
-		configMapNames = strings.Split(annotations[AnnotationConfigMaps], ",")
+// calculate the hashes of those references in the given annotations which affect the pod as a whole (instead of individual containers):
+// secret provider classes, arbitrary resources, implicitly referenced secrets and the image digest revision; each of them only yields
+// a hash if referenced, such that existing hashes remain stable (see combineHashes)
+func generatePodHashes(ctx context.Context, client ctrlclient.Reader, namespace string, annotations map[string]string, podSpec *corev1.PodSpec, mode string) ([]string, error) {
+	var hashes []string
+	if annotations[AnnotationSecretProviderClasses] != "" {
+		secretProviderClassHash, err := GenerateSecretProviderClassHash(ctx, client, namespace, SplitNames(annotations[AnnotationSecretProviderClasses]))
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, secretProviderClassHash)
 	}
-	if annotations[AnnotationSecrets] != "" {
-		secretNames = strings.Split(annotations[AnnotationSecrets], ",")
+	if annotations[AnnotationResources] != "" {
+		resourceHash, err := GenerateResourceHash(ctx, client, namespace, SplitNames(annotations[AnnotationResources]), mode == HashModeContent)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, resourceHash)
 	}
+	// implicitly referenced secrets are image pull secrets, and secrets of the service account
+	if IncludesImplicitSecrets(annotations) {
+		if podSpec == nil {
+			return nil, fmt.Errorf("implicit secrets can only be determined for workloads and pods")
+		}
+		secretNames, err := ImplicitSecretNames(ctx, client, namespace, podSpec)
+		if err != nil {
+			return nil, err
+		}
+		implicitHash, err := GenerateHashWithMode(ctx, client, namespace, mode, nil, secretNames)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, "implicit-secrets/"+implicitHash)
+	}
+	// the image digest revision is increased by the controller whenever the digest of a tracked image changes
+	if TracksImageDigests(annotations) && annotations[AnnotationImageDigestRevision] != "" {
+		hashes = append(hashes, "image-digest-revision/"+annotations[AnnotationImageDigestRevision])
+	}
+	return hashes, nil
+}
 
-	return GenerateHash(ctx, client, object.GetNamespace(), configMapNames, secretNames)
+// fold the given hashes (as returned by generatePodHashes) into the given hash
+func combineHashes(hash string, hashes []string) string {
+	for _, h := range hashes {
+		hash = sha256sum(hash + "\n" + h)
+	}
+	return hash
 }
 
 func sha256sum(s string) string {
@@ -0,0 +1,54 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ServiceAccountName returns the name of the service account used by the given pod spec.
+func ServiceAccountName(podSpec *corev1.PodSpec) string {
+	if podSpec.ServiceAccountName != "" {
+		return podSpec.ServiceAccountName
+	}
+	if podSpec.DeprecatedServiceAccount != "" {
+		return podSpec.DeprecatedServiceAccount
+	}
+	return "default"
+}
+
+// ImplicitSecretNames returns the names of the secrets implicitly referenced by the given pod spec, that is the image pull secrets
+// of the pod spec, and the secrets and image pull secrets of its service account (if existing).
+func ImplicitSecretNames(ctx context.Context, client ctrlclient.Reader, namespace string, podSpec *corev1.PodSpec) ([]string, error) {
+	var names []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, ref := range podSpec.ImagePullSecrets {
+		add(ref.Name)
+	}
+	serviceAccount := &corev1.ServiceAccount{}
+	if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: ServiceAccountName(podSpec)}, serviceAccount); err != nil {
+		if errors.IsNotFound(err) {
+			return names, nil
+		}
+		return nil, err
+	}
+	for _, ref := range serviceAccount.Secrets {
+		add(ref.Name)
+	}
+	for _, ref := range serviceAccount.ImagePullSecrets {
+		add(ref.Name)
+	}
+	return names, nil
+}
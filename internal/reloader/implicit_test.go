@@ -0,0 +1,70 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test implicit secrets", func() {
+	var scheme *runtime.Scheme
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	})
+
+	It("should collect image pull secrets and secrets of the service account", func() {
+		serviceAccount := &corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Namespace: "test", Name: "app"},
+			Secrets:          []corev1.ObjectReference{{Name: "token"}},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}, {Name: "mirror"}},
+		}
+		cli := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(serviceAccount).Build()
+		podSpec := &corev1.PodSpec{ServiceAccountName: "app", ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}}}
+
+		Expect(reloader.ImplicitSecretNames(ctx, cli, "test", podSpec)).To(Equal([]string{"registry", "token", "mirror"}))
+
+		podSpec.ServiceAccountName = ""
+		Expect(reloader.ServiceAccountName(podSpec)).To(Equal("default"))
+		Expect(reloader.ImplicitSecretNames(ctx, cli, "test", podSpec)).To(Equal([]string{"registry"}))
+	})
+
+	It("should include implicit secrets in the hash only if requested", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "registry"}, Data: map[string][]byte{"key": []byte("value")}}
+		cli := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app", Annotations: map[string]string{reloader.AnnotationConfigMaps: "config"}},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}}}},
+			},
+		}
+
+		plainHash, err := reloader.GenerateHashForObject(ctx, cli, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		deployment.Annotations[reloader.AnnotationIncludeImplicitSecrets] = "true"
+		implicitHash, err := reloader.GenerateHashForObject(ctx, cli, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(implicitHash).NotTo(Equal(plainHash))
+
+		Expect(cli.Get(ctx, ctrlclient.ObjectKeyFromObject(secret), secret)).To(Succeed())
+		secret.Data["key"] = []byte("other")
+		Expect(cli.Update(ctx, secret)).To(Succeed())
+		Expect(reloader.GenerateHashForObject(ctx, cli, deployment)).NotTo(Equal(implicitHash))
+	})
+})
@@ -0,0 +1,52 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamespaceScope restricts operation to a set of namespaces; the zero value means all namespaces.
+type NamespaceScope struct {
+	// Names of the namespaces in scope; empty means all namespaces.
+	Namespaces []string
+	// Label selector for the namespaces in scope; nil means all namespaces.
+	Selector labels.Selector
+}
+
+// IsZero returns whether the scope contains all namespaces.
+func (s NamespaceScope) IsZero() bool {
+	return len(s.Namespaces) == 0 && s.Selector == nil
+}
+
+// Contains returns whether the given namespace is in scope; namespaces which do not exist are considered out of scope
+// if a label selector is set.
+func (s NamespaceScope) Contains(ctx context.Context, client ctrlclient.Reader, namespace string) (bool, error) {
+	if namespace == "" {
+		// cluster-scoped objects are always in scope
+		return true, nil
+	}
+	if len(s.Namespaces) > 0 && !slices.Contains(s.Namespaces, namespace) {
+		return false, nil
+	}
+	if s.Selector == nil {
+		return true, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := client.Get(ctx, ctrlclient.ObjectKey{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return s.Selector.Matches(labels.Set(ns.Labels)), nil
+}
@@ -0,0 +1,55 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test namespace scope", func() {
+	var cli ctrlclient.Client
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		cli = fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"tenant": "a"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b", Labels: map[string]string{"tenant": "b"}}},
+		).Build()
+	})
+
+	It("should contain all namespaces if empty", func() {
+		scope := reloader.NamespaceScope{}
+		Expect(scope.IsZero()).To(BeTrue())
+		Expect(scope.Contains(ctx, cli, "tenant-a")).To(BeTrue())
+		Expect(scope.Contains(ctx, cli, "other")).To(BeTrue())
+	})
+
+	It("should respect namespace names and selector", func() {
+		scope := reloader.NamespaceScope{Namespaces: []string{"tenant-a", "other"}}
+		Expect(scope.Contains(ctx, cli, "tenant-a")).To(BeTrue())
+		Expect(scope.Contains(ctx, cli, "tenant-b")).To(BeFalse())
+
+		selector, err := labels.Parse("tenant in (a,c)")
+		Expect(err).NotTo(HaveOccurred())
+		scope = reloader.NamespaceScope{Selector: selector}
+		Expect(scope.Contains(ctx, cli, "tenant-a")).To(BeTrue())
+		Expect(scope.Contains(ctx, cli, "tenant-b")).To(BeFalse())
+		Expect(scope.Contains(ctx, cli, "other")).To(BeFalse())
+	})
+})
@@ -0,0 +1,70 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	KindConfigMap = "ConfigMap"
+	KindSecret    = "Secret"
+)
+
+// VisitReferences calls visit for every reference to a config map or secret within the given pod spec
+// (volumes, projected volumes, environment variables of containers, init containers and ephemeral containers);
+// kind is one of KindConfigMap, KindSecret, and name points to the referenced name, so it can be modified by the visitor.
+func VisitReferences(podSpec *corev1.PodSpec, visit func(kind string, name *string)) {
+	for i := range podSpec.Volumes {
+		volume := &podSpec.Volumes[i]
+		if volume.ConfigMap != nil {
+			visit(KindConfigMap, &volume.ConfigMap.Name)
+		}
+		if volume.Secret != nil {
+			visit(KindSecret, &volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for j := range volume.Projected.Sources {
+				source := &volume.Projected.Sources[j]
+				if source.ConfigMap != nil {
+					visit(KindConfigMap, &source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					visit(KindSecret, &source.Secret.Name)
+				}
+			}
+		}
+	}
+	visitContainer := func(env []corev1.EnvVar, envFrom []corev1.EnvFromSource) {
+		for i := range env {
+			if valueFrom := env[i].ValueFrom; valueFrom != nil {
+				if valueFrom.ConfigMapKeyRef != nil {
+					visit(KindConfigMap, &valueFrom.ConfigMapKeyRef.Name)
+				}
+				if valueFrom.SecretKeyRef != nil {
+					visit(KindSecret, &valueFrom.SecretKeyRef.Name)
+				}
+			}
+		}
+		for i := range envFrom {
+			if envFrom[i].ConfigMapRef != nil {
+				visit(KindConfigMap, &envFrom[i].ConfigMapRef.Name)
+			}
+			if envFrom[i].SecretRef != nil {
+				visit(KindSecret, &envFrom[i].SecretRef.Name)
+			}
+		}
+	}
+	for i := range podSpec.InitContainers {
+		visitContainer(podSpec.InitContainers[i].Env, podSpec.InitContainers[i].EnvFrom)
+	}
+	for i := range podSpec.Containers {
+		visitContainer(podSpec.Containers[i].Env, podSpec.Containers[i].EnvFrom)
+	}
+	for i := range podSpec.EphemeralContainers {
+		visitContainer(podSpec.EphemeralContainers[i].Env, podSpec.EphemeralContainers[i].EnvFrom)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ParseResourceReference parses a reference to an arbitrary resource, of the form group/version/kind:name
+// (or version/kind:name for resources of the core group).
+func ParseResourceReference(reference string) (schema.GroupVersionKind, string, error) {
+	typ, name, ok := strings.Cut(reference, ":")
+	if !ok || name == "" {
+		return schema.GroupVersionKind{}, "", fmt.Errorf("invalid resource reference (missing name): %s", reference)
+	}
+	var gvk schema.GroupVersionKind
+	switch parts := strings.Split(typ, "/"); len(parts) {
+	case 2:
+		gvk = schema.GroupVersionKind{Version: parts[0], Kind: parts[1]}
+	case 3:
+		if parts[0] == "" {
+			return schema.GroupVersionKind{}, "", fmt.Errorf("invalid resource reference (resources of the core group must be referenced as version/kind:name): %s", reference)
+		}
+		gvk = schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]}
+	default:
+		return schema.GroupVersionKind{}, "", fmt.Errorf("invalid resource reference (expected group/version/kind:name): %s", reference)
+	}
+	if gvk.Version == "" || gvk.Kind == "" {
+		return schema.GroupVersionKind{}, "", fmt.Errorf("invalid resource reference (expected group/version/kind:name): %s", reference)
+	}
+	return gvk, name, nil
+}
+
+// FormatResourceReference is the inverse of ParseResourceReference.
+func FormatResourceReference(gvk schema.GroupVersionKind, name string) string {
+	if gvk.Group == "" {
+		return gvk.Version + "/" + gvk.Kind + ":" + name
+	}
+	return gvk.Group + "/" + gvk.Version + "/" + gvk.Kind + ":" + name
+}
+
+// GenerateResourceHash calculates a hash from the given resource references (resolved in the given namespace, unless cluster-scoped);
+// depending on content, either uid and resource version, or the spec (everything except metadata and status, if there is no spec) is used.
+func GenerateResourceHash(ctx context.Context, client ctrlclient.Reader, namespace string, references []string, content bool) (string, error) {
+	s := ""
+	for _, reference := range references {
+		gvk, name, err := ParseResourceReference(reference)
+		if err != nil {
+			return "", err
+		}
+		s += "resource/" + namespace + "/" + reference + "/"
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(gvk)
+		// note: the namespace is ignored for cluster-scoped resources
+		err = client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: name}, object)
+		if err == nil {
+			if content {
+				s += ResourceContentHash(object) + "\n"
+			} else {
+				s += string(object.GetUID()) + "." + object.GetResourceVersion() + "\n"
+			}
+		} else if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			s += "\n"
+		} else {
+			return "", err
+		}
+	}
+	return sha256sum(s), nil
+}
+
+// ResourceContentHash returns a digest of the spec of the given object; if the object has no spec,
+// everything except metadata and status is considered.
+func ResourceContentHash(object *unstructured.Unstructured) string {
+	if spec, ok := object.Object["spec"]; ok {
+		return contentHash(spec)
+	}
+	content := make(map[string]any)
+	for key, value := range object.Object {
+		if key != "apiVersion" && key != "kind" && key != "metadata" && key != "status" {
+			content[key] = value
+		}
+	}
+	return contentHash(content)
+}
@@ -0,0 +1,66 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test resource references", func() {
+	It("should parse and format references", func() {
+		gvk, name, err := reloader.ParseResourceReference("example.com/v1alpha1/FeatureFlags:flags")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gvk).To(Equal(schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "FeatureFlags"}))
+		Expect(name).To(Equal("flags"))
+		Expect(reloader.FormatResourceReference(gvk, name)).To(Equal("example.com/v1alpha1/FeatureFlags:flags"))
+
+		gvk, name, err = reloader.ParseResourceReference("v1/ServiceAccount:default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloader.FormatResourceReference(gvk, name)).To(Equal("v1/ServiceAccount:default"))
+
+		for _, reference := range []string{"example.com/v1/FeatureFlags", "FeatureFlags:flags", "/v1/ServiceAccount:default", "example.com/v1/FeatureFlags:"} {
+			_, _, err = reloader.ParseResourceReference(reference)
+			Expect(err).To(HaveOccurred(), reference)
+		}
+	})
+
+	It("should derive the hash from the resource version or the spec", func() {
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		flags := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"enabled": true}}}
+		flags.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "FeatureFlags"})
+		flags.SetNamespace("test")
+		flags.SetName("flags")
+		cli := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(flags).Build()
+		references := []string{"example.com/v1alpha1/FeatureFlags:flags"}
+
+		versionHash, err := reloader.GenerateResourceHash(ctx, cli, "test", references, false)
+		Expect(err).NotTo(HaveOccurred())
+		contentHash, err := reloader.GenerateResourceHash(ctx, cli, "test", references, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cli.Get(ctx, ctrlclient.ObjectKeyFromObject(flags), flags)).To(Succeed())
+		flags.SetLabels(map[string]string{"foo": "bar"})
+		Expect(cli.Update(ctx, flags)).To(Succeed())
+		Expect(reloader.GenerateResourceHash(ctx, cli, "test", references, false)).NotTo(Equal(versionHash))
+		Expect(reloader.GenerateResourceHash(ctx, cli, "test", references, true)).To(Equal(contentHash))
+
+		Expect(unstructured.SetNestedField(flags.Object, false, "spec", "enabled")).To(Succeed())
+		Expect(cli.Update(ctx, flags)).To(Succeed())
+		Expect(reloader.GenerateResourceHash(ctx, cli, "test", references, true)).NotTo(Equal(contentHash))
+	})
+})
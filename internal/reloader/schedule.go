@@ -0,0 +1,236 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a recurring time window; its start times are given by a cron expression,
+// its length by a fixed duration.
+type MaintenanceWindow struct {
+	schedule *schedule
+	duration time.Duration
+}
+
+// ParseMaintenanceWindows parses a semicolon-separated list of maintenance windows.
+// Each window has the form '[TZ=<location>] <minute> <hour> <day of month> <month> <day of week> <duration>',
+// where the first five fields follow the usual cron syntax (lists, ranges, steps, month and weekday names),
+// and duration is a Go duration string, e.g. '0 2 * * SAT,SUN 3h'. If no location is given, UTC is assumed.
+func ParseMaintenanceWindows(s string) ([]MaintenanceWindow, error) {
+	var windows []MaintenanceWindow
+	for _, w := range strings.Split(s, ";") {
+		if strings.TrimSpace(w) == "" {
+			continue
+		}
+		window, err := parseMaintenanceWindow(w)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window '%s': %w", strings.TrimSpace(w), err)
+		}
+		windows = append(windows, window)
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("no maintenance windows specified")
+	}
+	return windows, nil
+}
+
+// NextMaintenanceWindow checks if the given time lies within one of the given maintenance windows.
+// If not, the start time of the next window is returned as well; a zero time is returned if none of the windows
+// will ever start.
+func NextMaintenanceWindow(windows []MaintenanceWindow, now time.Time) (bool, time.Time) {
+	var next time.Time
+	for _, window := range windows {
+		start := window.schedule.next(now.Add(-window.duration))
+		if start.IsZero() {
+			continue
+		}
+		if !start.After(now) {
+			return true, time.Time{}
+		}
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return false, next
+}
+
+func parseMaintenanceWindow(s string) (MaintenanceWindow, error) {
+	fields := strings.Fields(s)
+	location := time.UTC
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "TZ=") {
+		loc, err := time.LoadLocation(strings.TrimPrefix(fields[0], "TZ="))
+		if err != nil {
+			return MaintenanceWindow{}, err
+		}
+		location = loc
+		fields = fields[1:]
+	}
+	if len(fields) != 6 {
+		return MaintenanceWindow{}, fmt.Errorf("expected 5 cron fields and a duration, got %d fields", len(fields))
+	}
+	schedule, err := parseSchedule(fields[0:5], location)
+	if err != nil {
+		return MaintenanceWindow{}, err
+	}
+	duration, err := time.ParseDuration(fields[5])
+	if err != nil {
+		return MaintenanceWindow{}, err
+	}
+	if duration <= 0 {
+		return MaintenanceWindow{}, fmt.Errorf("duration must be positive")
+	}
+	return MaintenanceWindow{schedule: schedule, duration: duration}, nil
+}
+
+type schedule struct {
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	domStar  bool
+	dowStar  bool
+	location *time.Location
+}
+
+type scheduleField struct {
+	min   int
+	max   int
+	names []string
+}
+
+var (
+	minuteField = scheduleField{min: 0, max: 59}
+	hourField   = scheduleField{min: 0, max: 23}
+	domField    = scheduleField{min: 1, max: 31}
+	monthField  = scheduleField{min: 1, max: 12, names: []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	dowField    = scheduleField{min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+func parseSchedule(fields []string, location *time.Location) (*schedule, error) {
+	var err error
+	s := &schedule{location: location}
+	if s.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if s.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if s.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if s.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	// sunday may be given as 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = fields[2] == "*"
+	s.dowStar = fields[4] == "*"
+	return s, nil
+}
+
+func (f scheduleField) parse(s string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in '%s'", part)
+			}
+			step = n
+		}
+		var lo, hi int
+		if rng == "*" {
+			lo, hi = f.min, f.max
+		} else if a, b, isRange := strings.Cut(rng, "-"); isRange {
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, err
+			}
+		} else {
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if hasStep {
+				hi = f.max
+			}
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("invalid range '%s'", rng)
+		}
+		for i := lo; i <= hi; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func (f scheduleField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d,%d]", n, f.min, f.max)
+	}
+	return n, nil
+}
+
+// return the first matching time strictly after t (truncated to minutes), or the zero time if there is no such time within the next five years
+func (s *schedule) next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
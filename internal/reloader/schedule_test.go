@@ -0,0 +1,76 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test maintenance windows", func() {
+	// 2026-10-14 is a wednesday
+	date := func(day int, hour int, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	It("should reject invalid windows", func() {
+		for _, s := range []string{"", "0 2 * * *", "0 2 * * * 0h", "60 2 * * * 1h", "0 2 * * FOO 1h", "0 2 * * * 1h;x", "TZ=Foo/Bar 0 2 * * * 1h"} {
+			_, err := reloader.ParseMaintenanceWindows(s)
+			Expect(err).To(HaveOccurred(), "window: %s", s)
+		}
+	})
+
+	It("should detect active windows", func() {
+		windows, err := reloader.ParseMaintenanceWindows("0 2 * * * 2h")
+		Expect(err).NotTo(HaveOccurred())
+		active, _ := reloader.NextMaintenanceWindow(windows, date(14, 2, 0))
+		Expect(active).To(BeTrue())
+		active, _ = reloader.NextMaintenanceWindow(windows, date(14, 3, 59))
+		Expect(active).To(BeTrue())
+		active, next := reloader.NextMaintenanceWindow(windows, date(14, 4, 0))
+		Expect(active).To(BeFalse())
+		Expect(next).To(Equal(date(15, 2, 0)))
+	})
+
+	It("should return the earliest next window", func() {
+		windows, err := reloader.ParseMaintenanceWindows("30 22 * * MON-FRI 1h; 0 2 * * SAT,SUN 3h")
+		Expect(err).NotTo(HaveOccurred())
+		active, next := reloader.NextMaintenanceWindow(windows, date(14, 12, 0))
+		Expect(active).To(BeFalse())
+		Expect(next).To(Equal(date(14, 22, 30)))
+		active, next = reloader.NextMaintenanceWindow(windows, date(16, 23, 30))
+		Expect(active).To(BeFalse())
+		Expect(next).To(Equal(date(17, 2, 0)))
+	})
+
+	It("should handle day of month and day of week according to cron semantics", func() {
+		windows, err := reloader.ParseMaintenanceWindows("0 0 1 * SUN 1h")
+		Expect(err).NotTo(HaveOccurred())
+		_, next := reloader.NextMaintenanceWindow(windows, date(14, 12, 0))
+		Expect(next).To(Equal(date(18, 0, 0)))
+		_, next = reloader.NextMaintenanceWindow(windows, date(25, 12, 0))
+		Expect(next).To(Equal(time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("should respect time zones", func() {
+		windows, err := reloader.ParseMaintenanceWindows("TZ=Europe/Berlin 0 2 * * * 1h")
+		Expect(err).NotTo(HaveOccurred())
+		_, next := reloader.NextMaintenanceWindow(windows, date(14, 12, 0))
+		Expect(next.Equal(date(15, 0, 0))).To(BeTrue())
+	})
+
+	It("should never return a start for impossible schedules", func() {
+		windows, err := reloader.ParseMaintenanceWindows("0 0 30 FEB * 1h")
+		Expect(err).NotTo(HaveOccurred())
+		active, next := reloader.NextMaintenanceWindow(windows, date(14, 12, 0))
+		Expect(active).To(BeFalse())
+		Expect(next.IsZero()).To(BeTrue())
+	})
+})
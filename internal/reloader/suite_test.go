@@ -156,6 +156,35 @@ var _ = Describe("Test hash computation (from object annotation)", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(hash).To(Equal(expectedHash))
 	})
+
+	It("should derive the hash from the content if requested", func() {
+		configMapNames := []string{"test1", "test3"}
+		secretNames := []string{"test1"}
+		deployment := buildDeployment(namespace, "test", configMapNames, secretNames)
+		deployment.Annotations[reloader.AnnotationHashMode] = reloader.HashModeContent
+		hash, err := reloader.GenerateHashForObject(ctx, cli, deployment)
+		Expect(err).NotTo(HaveOccurred())
+
+		// same content, but different uid and resource version
+		otherCli := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+			buildConfigMap(namespace, "test1", "key", "value"),
+			buildSecret(namespace, "test1", "key", "value"),
+		).Build()
+		otherConfigMap := &corev1.ConfigMap{}
+		Expect(otherCli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "test1"}, otherConfigMap)).To(Succeed())
+		otherConfigMap.UID = "other"
+		otherConfigMap.Labels = map[string]string{"foo": "bar"}
+		Expect(otherCli.Update(ctx, otherConfigMap)).To(Succeed())
+		otherHash, err := reloader.GenerateHashForObject(ctx, otherCli, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otherHash).To(Equal(hash))
+
+		otherConfigMap.Data["key"] = "other value"
+		Expect(otherCli.Update(ctx, otherConfigMap)).To(Succeed())
+		otherHash, err = reloader.GenerateHashForObject(ctx, otherCli, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otherHash).NotTo(Equal(hash))
+	})
 })
 
 func buildConfigMap(namespace string, name string, key string, value string) *corev1.ConfigMap {
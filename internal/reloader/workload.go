@@ -0,0 +1,95 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reloader
+
+import (
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PodTemplate returns the pod template of the given workload object, or nil if the object kind is not supported.
+func PodTemplate(object runtime.Object) *corev1.PodTemplateSpec {
+	switch obj := object.(type) {
+	// add additional workload types here
+	case *appsv1.Deployment:
+		return &obj.Spec.Template
+	case *appsv1.StatefulSet:
+		return &obj.Spec.Template
+	case *appsv1.DaemonSet:
+		return &obj.Spec.Template
+	default:
+		return nil
+	}
+}
+
+// PodSpec returns the pod spec of the given pod or workload object, or nil if the object kind is not supported.
+func PodSpec(object runtime.Object) *corev1.PodSpec {
+	if pod, ok := object.(*corev1.Pod); ok {
+		return &pod.Spec
+	}
+	if podTemplate := PodTemplate(object); podTemplate != nil {
+		return &podTemplate.Spec
+	}
+	return nil
+}
+
+// AppliedHash returns the configuration hash the running pods of the given workload are currently using;
+// for the restart strategy, this is the hash maintained on the pod template by the webhook, otherwise, the hash
+// recorded on the workload by the controller after the last successful in-place reload.
+func AppliedHash(object ctrlclient.Object, strategy string) string {
+	if strategy == StrategyRestart {
+		if podTemplate := PodTemplate(object); podTemplate != nil {
+			return podTemplate.Annotations[AnnotationConfigHash]
+		}
+		return ""
+	}
+	return object.GetAnnotations()[AnnotationAppliedConfigHash]
+}
+
+// InjectHash sets the given configuration hash as annotation on the given workload; if the workload is updated afterwards,
+// the webhook validates the hash, removes the annotation again, and updates the pod template (thus triggering a restart of the pods).
+func InjectHash(object metav1.Object, hash string) {
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AnnotationConfigHash] = hash
+	object.SetAnnotations(annotations)
+}
+
+// HasReferences returns whether the given annotations contain references to config maps, secrets, secret provider classes or other resources,
+// or request tracking of image digests or the inclusion of implicit secrets.
+func HasReferences(annotations map[string]string) bool {
+	return annotations[AnnotationConfigMaps] != "" || annotations[AnnotationSecrets] != "" || annotations[AnnotationSecretProviderClasses] != "" || annotations[AnnotationResources] != "" ||
+		TracksImageDigests(annotations) || IncludesImplicitSecrets(annotations)
+}
+
+// SplitNames splits a comma-separated list of names, as used in the reference annotations; an empty string yields no names.
+func SplitNames(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// TracksImageDigests returns whether the given annotations request tracking of image digests.
+func TracksImageDigests(annotations map[string]string) bool {
+	track, _ := strconv.ParseBool(annotations[AnnotationTrackImageDigests])
+	return track
+}
+
+// IncludesImplicitSecrets returns whether the given annotations request the inclusion of implicitly referenced secrets
+// (image pull secrets, and secrets of the service account) in the configuration hash.
+func IncludesImplicitSecrets(annotations map[string]string) bool {
+	include, _ := strconv.ParseBool(annotations[AnnotationIncludeImplicitSecrets])
+	return include
+}
@@ -0,0 +1,122 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+// in-memory record of the current configuration hashes, keyed by the reference annotations of the workload;
+// it tells whether the hash on an existing pod template is still current, and entries are invalidated whenever
+// a referenced config map or secret changes; invalidations are counted per namespace, so that changes in other
+// namespaces do not interfere with hashes being calculated concurrently
+type hashCache struct {
+	mutex       sync.Mutex
+	generations map[string]uint64
+	entries     map[hashCacheKey]string
+}
+
+type hashCacheKey struct {
+	namespace  string
+	configMaps string
+	secrets    string
+	hashMode   string
+}
+
+func newHashCache() *hashCache {
+	return &hashCache{generations: make(map[string]uint64), entries: make(map[hashCacheKey]string)}
+}
+
+func hashCacheKeyOf(namespace string, annotations map[string]string) hashCacheKey {
+	return hashCacheKey{
+		namespace:  namespace,
+		configMaps: annotations[reloader.AnnotationConfigMaps],
+		secrets:    annotations[reloader.AnnotationSecrets],
+		hashMode:   annotations[reloader.AnnotationHashMode],
+	}
+}
+
+// return the cached hash for the given key, and the current generation of the key's namespace
+func (c *hashCache) get(key hashCacheKey) (string, bool, uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	hash, ok := c.entries[key]
+	return hash, ok, c.generations[key.namespace]
+}
+
+// store a hash which was calculated when the key's namespace was at the given generation; the hash is discarded
+// if there were invalidations in the namespace since then, because it might have been calculated from outdated objects
+func (c *hashCache) set(key hashCacheKey, hash string, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if generation != c.generations[key.namespace] {
+		return
+	}
+	c.entries[key] = hash
+}
+
+// drop all entries referencing the given config map or secret
+func (c *hashCache) invalidate(kind string, namespace string, name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generations[namespace]++
+	for key := range c.entries {
+		if key.namespace != namespace {
+			continue
+		}
+		names := key.configMaps
+		if kind == "Secret" {
+			names = key.secrets
+		}
+		if slices.Contains(reloader.SplitNames(names), name) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// register event handlers invalidating the cache on the informers of the given manager;
+// since the cache of the manager is not leader-elected, this happens on all replicas
+func (c *hashCache) setupWithManager(mgr ctrl.Manager) error {
+	for kind, object := range map[string]ctrlclient.Object{"ConfigMap": &corev1.ConfigMap{}, "Secret": &corev1.Secret{}} {
+		informer, err := mgr.GetCache().GetInformer(context.Background(), object)
+		if err != nil {
+			return err
+		}
+		invalidate := func(obj any) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if object, ok := obj.(ctrlclient.Object); ok {
+				c.invalidate(kind, object.GetNamespace(), object.GetName())
+			}
+		}
+		if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: invalidate,
+			UpdateFunc: func(oldObj any, newObj any) {
+				if oldObject, ok := oldObj.(ctrlclient.Object); ok {
+					if newObject, ok := newObj.(ctrlclient.Object); ok && oldObject.GetResourceVersion() == newObject.GetResourceVersion() {
+						// periodic resync
+						return
+					}
+				}
+				invalidate(newObj)
+			},
+			DeleteFunc: invalidate,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
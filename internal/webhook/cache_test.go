@@ -0,0 +1,101 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test configuration hash cache", func() {
+	var m *mutator
+	var deployment *appsv1.Deployment
+	var hash string
+
+	// change the config map without invalidating the cache, so that reused hashes can be told apart from recalculated ones
+	changeConfigMap := func() {
+		configMap := &corev1.ConfigMap{}
+		ExpectWithOffset(1, m.client.Get(ctx, ctrlclient.ObjectKey{Namespace: "test", Name: "config"}, configMap)).To(Succeed())
+		configMap.Data["key"] = "changed"
+		ExpectWithOffset(1, m.client.Update(ctx, configMap)).To(Succeed())
+	}
+
+	generateHash := func(object *appsv1.Deployment, oldObject runtime.Object) string {
+		hash, err := m.generateHash(ctx, object, oldObject)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return hash
+	}
+
+	BeforeEach(func() {
+		m = newTestMutator(Options{}, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "value"},
+		})
+		deployment = buildDeployment("test", "test", map[string]string{reloader.AnnotationConfigMaps: "config"})
+		hash = generateHash(deployment, nil)
+		deployment.Spec.Template.Annotations = map[string]string{reloader.AnnotationConfigHash: hash}
+		changeConfigMap()
+	})
+
+	It("should reuse the hash of the existing pod template on updates not touching the references", func() {
+		newDeployment := deployment.DeepCopy()
+		newDeployment.Spec.Replicas = &[]int32{3}[0]
+		Expect(generateHash(newDeployment, deployment)).To(Equal(hash))
+	})
+
+	It("should recalculate the hash after a referenced object changed", func() {
+		m.cache.invalidate("ConfigMap", "test", "config")
+		Expect(generateHash(deployment.DeepCopy(), deployment)).NotTo(Equal(hash))
+	})
+
+	It("should not be invalidated by changes of other objects", func() {
+		m.cache.invalidate("ConfigMap", "test", "other")
+		m.cache.invalidate("Secret", "test", "config")
+		m.cache.invalidate("ConfigMap", "other", "config")
+		Expect(generateHash(deployment.DeepCopy(), deployment)).To(Equal(hash))
+	})
+
+	It("should recalculate the hash if the hash of the existing pod template is not current", func() {
+		deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash] = "outdated"
+		Expect(generateHash(deployment.DeepCopy(), deployment)).NotTo(Equal(hash))
+	})
+
+	It("should recalculate the hash on creation, on changed references, and on injected hashes", func() {
+		Expect(generateHash(deployment.DeepCopy(), nil)).NotTo(Equal(hash))
+
+		newDeployment := deployment.DeepCopy()
+		newDeployment.Annotations[reloader.AnnotationSecrets] = "secret"
+		Expect(generateHash(newDeployment, deployment)).NotTo(Equal(hash))
+
+		newDeployment = deployment.DeepCopy()
+		newDeployment.Annotations[reloader.AnnotationConfigHash] = "injected"
+		Expect(generateHash(newDeployment, deployment)).NotTo(Equal(hash))
+	})
+
+	It("should discard hashes calculated before an invalidation in the same namespace", func() {
+		key := hashCacheKeyOf("test", deployment.Annotations)
+
+		_, _, generation := m.cache.get(key)
+		m.cache.invalidate("ConfigMap", "other", "config")
+		m.cache.set(key, "other", generation)
+		cachedHash, _, _ := m.cache.get(key)
+		Expect(cachedHash).To(Equal("other"))
+
+		_, _, generation = m.cache.get(key)
+		m.cache.invalidate("ConfigMap", "test", "other")
+		m.cache.set(key, "stale", generation)
+		cachedHash, _, _ = m.cache.get(key)
+		Expect(cachedHash).To(Equal("other"))
+	})
+})
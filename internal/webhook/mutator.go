@@ -10,6 +10,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -28,6 +30,8 @@ type mutator struct {
 	scheme  *runtime.Scheme
 	client  ctrlclient.Client
 	decoder admission.Decoder
+	cache   *hashCache
+	options Options
 }
 
 func (m *mutator) Handle(ctx context.Context, req admission.Request) admission.Response {
@@ -35,6 +39,13 @@ func (m *mutator) Handle(ctx context.Context, req admission.Request) admission.R
 	log = log.WithValues("kind", req.Kind, "namespace", req.Namespace, "name", req.Name)
 	ctx = ctrl.LoggerInto(ctx, log)
 
+	if inScope, err := m.options.NamespaceScope.Contains(ctx, m.client, req.Namespace); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	} else if !inScope {
+		log.V(1).Info("ignoring request for namespace out of scope")
+		return admission.Allowed("")
+	}
+
 	gvk := schema.GroupVersionKind{
 		Group:   req.Kind.Group,
 		Version: req.Kind.Version,
@@ -51,8 +62,27 @@ func (m *mutator) Handle(ctx context.Context, req admission.Request) admission.R
 
 	switch req.Operation {
 	case admissionv1.Create, admissionv1.Update:
-		if err := m.handleCreateOrUpdate(ctx, object); err != nil {
-			return admission.Errored(http.StatusBadRequest, err)
+		if pod, ok := object.(*corev1.Pod); ok {
+			// pods are only mutated upon creation
+			if req.Operation != admissionv1.Create {
+				return admission.Allowed("")
+			}
+			if err := m.handlePodCreate(ctx, pod, req.Namespace); err != nil {
+				return admission.Errored(http.StatusBadRequest, err)
+			}
+		} else {
+			var oldObject runtime.Object
+			if req.Operation == admissionv1.Update {
+				if oldObject, err = m.scheme.New(gvk); err != nil {
+					return admission.Errored(http.StatusBadRequest, err)
+				}
+				if err := m.decoder.DecodeRaw(req.OldObject, oldObject); err != nil {
+					return admission.Errored(http.StatusBadRequest, err)
+				}
+			}
+			if err := m.handleCreateOrUpdate(ctx, gvk, object, oldObject, req.DryRun != nil && *req.DryRun); err != nil {
+				return admission.Errored(http.StatusBadRequest, err)
+			}
 		}
 	default:
 		return admission.Errored(http.StatusBadRequest, fmt.Errorf("this admission webhook may only be called for create/update operations"))
@@ -64,7 +94,7 @@ func (m *mutator) Handle(ctx context.Context, req admission.Request) admission.R
 	return admission.PatchResponseFromRaw(req.Object.Raw, rawObject)
 }
 
-func (m *mutator) handleCreateOrUpdate(ctx context.Context, object runtime.Object) error {
+func (m *mutator) handleCreateOrUpdate(ctx context.Context, gvk schema.GroupVersionKind, object runtime.Object, oldObject runtime.Object, dryRun bool) error {
 	log := ctrl.LoggerFrom(ctx)
 	log.V(1).Info("running mutation webhook")
 
@@ -88,16 +118,17 @@ func (m *mutator) handleCreateOrUpdate(ctx context.Context, object runtime.Objec
 		return fmt.Errorf("webhook called with unsupported object kind: %s", object.GetObjectKind().GroupVersionKind())
 	}
 
-	if objMeta.Annotations[reloader.AnnotationConfigMaps] == "" && objMeta.Annotations[reloader.AnnotationSecrets] == "" {
+	if !reloader.HasReferences(objMeta.Annotations) {
 		return nil
 	}
 
-	hash, err := reloader.GenerateHashForObject(ctx, m.client, objMeta)
+	hash, err := m.generateHash(ctx, object.(metav1.Object), oldObject)
 	if err != nil {
 		return err
 	}
 
-	if injectedHash, ok := objMeta.Annotations[reloader.AnnotationConfigHash]; ok {
+	injectedHash, injected := objMeta.Annotations[reloader.AnnotationConfigHash]
+	if injected {
 		log.Info("got injected configuration hash (probably set by controller due to config map or secret change)")
 		if injectedHash != hash {
 			return fmt.Errorf("injected hash does not match calculated hash")
@@ -106,6 +137,83 @@ func (m *mutator) handleCreateOrUpdate(ctx context.Context, object runtime.Objec
 	}
 
 	currentHash := podTemplate.Annotations[reloader.AnnotationConfigHash]
+	if m.options.HashFieldManager != "" && oldObject != nil && !injected {
+		// the hash on the pod template is maintained by the controller through server-side apply; the webhook only
+		// validates changes of the hash, and restores the hash if it was dropped (e.g. by an update replacing the whole object)
+		oldHash := ""
+		if oldPodTemplate := reloader.PodTemplate(oldObject); oldPodTemplate != nil {
+			oldHash = oldPodTemplate.Annotations[reloader.AnnotationConfigHash]
+		}
+		if currentHash == "" && oldHash != "" {
+			log.Info("restoring configuration hash")
+			if podTemplate.Annotations == nil {
+				podTemplate.Annotations = make(map[string]string)
+			}
+			podTemplate.Annotations[reloader.AnnotationConfigHash] = oldHash
+			return nil
+		}
+		if currentHash != oldHash {
+			if currentHash != hash {
+				return fmt.Errorf("applied hash does not match calculated hash")
+			}
+			// the controller changed the hash; continue as if it was injected
+			injected = true
+		} else if currentHash != "" {
+			return nil
+		}
+	}
+	if strategy := objMeta.Annotations[reloader.AnnotationStrategy]; strategy != "" && strategy != reloader.StrategyRestart && !injected {
+		// workloads with in-place reload strategies are reloaded by the controller without changing the pod template;
+		// the pod template is only updated if the controller explicitly requests a restart by injecting the hash;
+		// if the hash applied in-place is not yet known, it is initialized with the hash the pods were started with
+		if objMeta.Annotations[reloader.AnnotationAppliedConfigHash] == "" {
+			if currentHash != "" {
+				objMeta.Annotations[reloader.AnnotationAppliedConfigHash] = currentHash
+			} else {
+				objMeta.Annotations[reloader.AnnotationAppliedConfigHash] = hash
+			}
+		}
+		// same for the per-container hashes (restart-containers strategy); if the pods were started with an outdated
+		// configuration, the per-container hashes are left unset, which makes the controller restart all containers
+		if strategy == reloader.StrategyRestartContainers && objMeta.Annotations[reloader.AnnotationAppliedContainerConfigHashes] == "" && (currentHash == "" || currentHash == hash) {
+			if err := m.setContainerHashes(ctx, object.(ctrlclient.Object)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	immutableConfig, _ := strconv.ParseBool(objMeta.Annotations[reloader.AnnotationImmutableConfig])
+	var existingSnapshots snapshotIndex
+	if immutableConfig && m.options.EnableImmutableConfig {
+		if existingSnapshots, err = m.listSnapshots(ctx, objMeta.Namespace); err != nil {
+			return err
+		}
+	}
+	if immutableConfig && m.options.EnableImmutableConfig && oldObject != nil && !injected && currentHash != "" && refersToSnapshotsOnly(objMeta, podTemplate, existingSnapshots) {
+		// the snapshots referenced by the pod template pin its configuration, so updates not triggered by the controller keep these references
+		// along with the matching hash (e.g. updates not touching the pod template, or kubectl rollout undo restoring a previous pod template)
+		log.V(1).Info("keeping snapshot references and configuration hash")
+		return nil
+	}
+
+	if oldObject != nil && !injected {
+		// updates not triggered by the controller (e.g. by a gitops tool re-applying the manifest) must not roll out a changed configuration
+		// outside of the workload's maintenance windows; instead, the previous hash is kept, and the controller triggers the reload later
+		oldPodTemplate := reloader.PodTemplate(oldObject)
+		if oldHash := oldPodTemplate.Annotations[reloader.AnnotationConfigHash]; oldHash != "" && oldHash != hash && !m.inMaintenanceWindow(ctx, objMeta.Annotations, time.Now()) {
+			log.Info("keeping previous configuration hash until next maintenance window")
+			if podTemplate.Annotations == nil {
+				podTemplate.Annotations = make(map[string]string)
+			}
+			podTemplate.Annotations[reloader.AnnotationConfigHash] = oldHash
+			if immutableConfig && m.options.EnableImmutableConfig {
+				keepSnapshotReferences(objMeta, oldPodTemplate, podTemplate, existingSnapshots)
+			}
+			return nil
+		}
+	}
+
 	if currentHash == "" {
 		log.Info("setting initial configuration hash")
 	} else if hash != currentHash {
@@ -116,6 +224,110 @@ func (m *mutator) handleCreateOrUpdate(ctx context.Context, object runtime.Objec
 		podTemplate.Annotations = make(map[string]string)
 	}
 	podTemplate.Annotations[reloader.AnnotationConfigHash] = hash
+	if objMeta.Annotations[reloader.AnnotationAppliedConfigHash] != "" {
+		// pods will be restarted, so they will pick up the current configuration
+		objMeta.Annotations[reloader.AnnotationAppliedConfigHash] = hash
+	}
+	if objMeta.Annotations[reloader.AnnotationAppliedContainerConfigHashes] != "" {
+		if err := m.setContainerHashes(ctx, object.(ctrlclient.Object)); err != nil {
+			return err
+		}
+	}
+
+	if immutableConfig {
+		if !m.options.EnableImmutableConfig {
+			log.Info("ignoring annotation because immutable config is not enabled", "annotation", reloader.AnnotationImmutableConfig)
+		} else if err := m.snapshotReferences(ctx, gvk, objMeta, podTemplate, existingSnapshots, injected, dryRun); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// check if the given time lies within one of the maintenance windows given by the workload's annotations (or if there are no such windows);
+// as in the controller, invalid maintenance windows are treated as never active
+func (m *mutator) inMaintenanceWindow(ctx context.Context, annotations map[string]string, now time.Time) bool {
+	if annotations[reloader.AnnotationMaintenanceWindows] == "" {
+		return true
+	}
+	windows, err := reloader.ParseMaintenanceWindows(annotations[reloader.AnnotationMaintenanceWindows])
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "error parsing maintenance windows")
+		return false
+	}
+	active, _ := reloader.NextMaintenanceWindow(windows, now)
+	return active
+}
+
+// calculate the configuration hash of the given workload; if an update did not touch the reference annotations (e.g. when scaling),
+// and the hash on the existing pod template is still current (i.e. no referenced object changed since it was calculated), that hash
+// is reused instead of reading the references again
+func (m *mutator) generateHash(ctx context.Context, object metav1.Object, oldObject runtime.Object) (string, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	annotations := object.GetAnnotations()
+	// note: changes of secret provider classes, other resources, image digests and implicit secrets are not tracked by the cache, so such workloads are never cached
+	if annotations[reloader.AnnotationSecretProviderClasses] != "" || annotations[reloader.AnnotationResources] != "" || reloader.TracksImageDigests(annotations) || reloader.IncludesImplicitSecrets(annotations) {
+		return reloader.GenerateHashForObject(ctx, m.client, object)
+	}
+
+	key := hashCacheKeyOf(object.GetNamespace(), annotations)
+	_, injected := annotations[reloader.AnnotationConfigHash]
+	if oldObjMeta, ok := oldObject.(metav1.Object); ok && !injected && hashCacheKeyOf(oldObjMeta.GetNamespace(), oldObjMeta.GetAnnotations()) == key {
+		if oldPodTemplate := reloader.PodTemplate(oldObject); oldPodTemplate != nil {
+			if oldHash := oldPodTemplate.Annotations[reloader.AnnotationConfigHash]; oldHash != "" {
+				if hash, ok, _ := m.cache.get(key); ok && hash == oldHash {
+					log.V(1).Info("reusing configuration hash of existing pod template")
+					return oldHash, nil
+				}
+			}
+		}
+	}
+
+	_, _, generation := m.cache.get(key)
+	hash, err := reloader.GenerateHashForObject(ctx, m.client, object)
+	if err != nil {
+		return "", err
+	}
+	m.cache.set(key, hash, generation)
+	return hash, nil
+}
+
+// record the per-container hashes of the given workload's pod template on the workload
+func (m *mutator) setContainerHashes(ctx context.Context, object ctrlclient.Object) error {
+	hashes, err := reloader.GenerateContainerHashesForObject(ctx, m.client, object)
+	if err != nil {
+		return err
+	}
+	annotations := object.GetAnnotations()
+	annotations[reloader.AnnotationAppliedContainerConfigHashes] = reloader.FormatContainerHashes(hashes)
+	object.SetAnnotations(annotations)
+	return nil
+}
+
+// set the configuration hash on pods which are directly annotated with references to config maps or secrets;
+// this allows the controller to detect (and evict) pods running with an outdated configuration
+func (m *mutator) handlePodCreate(ctx context.Context, pod *corev1.Pod, namespace string) error {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(1).Info("running mutation webhook for pod")
+
+	if !reloader.HasReferences(pod.Annotations) {
+		return nil
+	}
+
+	// pods created by controllers (e.g. through generateName) may come without namespace
+	podWithNamespace := pod.DeepCopy()
+	if podWithNamespace.Namespace == "" {
+		podWithNamespace.Namespace = namespace
+	}
+	hash, err := reloader.GenerateHashForObject(ctx, m.client, podWithNamespace)
+	if err != nil {
+		return err
+	}
+
+	log.Info("setting configuration hash on pod")
+	pod.Annotations[reloader.AnnotationConfigHash] = hash
 
 	return nil
 }
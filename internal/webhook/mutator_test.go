@@ -0,0 +1,101 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test mutation of workloads", func() {
+	var m *mutator
+	var gvk = appsv1.SchemeGroupVersion.WithKind("Deployment")
+	var oldDeployment *appsv1.Deployment
+	var oldHash string
+
+	// a maintenance window of one minute, starting in twelve hours (so the current time is outside of it)
+	inactiveWindow := func() string {
+		start := time.Now().UTC().Add(12 * time.Hour)
+		return fmt.Sprintf("%d %d * * * 1m", start.Minute(), start.Hour())
+	}
+
+	BeforeEach(func() {
+		m = newTestMutator(Options{}, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "value"},
+		})
+
+		oldDeployment = buildDeployment("test", "test", map[string]string{reloader.AnnotationConfigMaps: "config"})
+		Expect(m.handleCreateOrUpdate(ctx, gvk, oldDeployment, nil, false)).To(Succeed())
+		oldHash = oldDeployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]
+		Expect(oldHash).NotTo(BeEmpty())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(m.client.Get(ctx, ctrlclient.ObjectKey{Namespace: "test", Name: "config"}, configMap)).To(Succeed())
+		configMap.Data["key"] = "changed"
+		Expect(m.client.Update(ctx, configMap)).To(Succeed())
+		// done by the informer event handlers outside of tests
+		m.cache.invalidate("ConfigMap", "test", "config")
+	})
+
+	It("should update the hash on updates without maintenance windows", func() {
+		deployment := oldDeployment.DeepCopy()
+		Expect(m.handleCreateOrUpdate(ctx, gvk, deployment, oldDeployment, false)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).NotTo(BeEmpty())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).NotTo(Equal(oldHash))
+	})
+
+	It("should update the hash on updates within maintenance windows", func() {
+		oldDeployment.Annotations[reloader.AnnotationMaintenanceWindows] = "* * * * * 1h"
+		deployment := oldDeployment.DeepCopy()
+		Expect(m.handleCreateOrUpdate(ctx, gvk, deployment, oldDeployment, false)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).NotTo(Equal(oldHash))
+	})
+
+	It("should keep the previous hash on updates outside of maintenance windows", func() {
+		oldDeployment.Annotations[reloader.AnnotationMaintenanceWindows] = inactiveWindow()
+		deployment := oldDeployment.DeepCopy()
+		deployment.Spec.Template.Annotations = nil
+		deployment.Spec.Replicas = &[]int32{3}[0]
+		Expect(m.handleCreateOrUpdate(ctx, gvk, deployment, oldDeployment, false)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).To(Equal(oldHash))
+	})
+
+	It("should keep the previous hash on updates if the maintenance windows are invalid", func() {
+		oldDeployment.Annotations[reloader.AnnotationMaintenanceWindows] = "invalid"
+		deployment := oldDeployment.DeepCopy()
+		Expect(m.handleCreateOrUpdate(ctx, gvk, deployment, oldDeployment, false)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).To(Equal(oldHash))
+	})
+
+	It("should apply hashes injected by the controller outside of maintenance windows", func() {
+		oldDeployment.Annotations[reloader.AnnotationMaintenanceWindows] = inactiveWindow()
+		deployment := oldDeployment.DeepCopy()
+		hash, err := reloader.GenerateHashForObject(ctx, m.client, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		reloader.InjectHash(deployment, hash)
+		Expect(m.handleCreateOrUpdate(ctx, gvk, deployment, oldDeployment, false)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).To(Equal(hash))
+		Expect(deployment.Annotations).NotTo(HaveKey(reloader.AnnotationConfigHash))
+	})
+
+	It("should set the hash on creation outside of maintenance windows", func() {
+		deployment := buildDeployment("test", "other", map[string]string{reloader.AnnotationConfigMaps: "config", reloader.AnnotationMaintenanceWindows: inactiveWindow()})
+		Expect(m.handleCreateOrUpdate(ctx, gvk, deployment, nil, false)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).NotTo(BeEmpty())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).NotTo(Equal(oldHash))
+	})
+})
@@ -0,0 +1,192 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// interval in which the webhook configuration is reconciled
+const registrationInterval = 5 * time.Minute
+
+// SupportedWorkloadResources are the workload resources (of api group apps) the webhook can handle.
+var SupportedWorkloadResources = []string{
+	// add additional workload types here
+	"deployments",
+	"statefulsets",
+	"daemonsets",
+}
+
+// RegistrationOptions controls how the webhook registers itself.
+type RegistrationOptions struct {
+	// Name of the MutatingWebhookConfiguration.
+	Name string
+	// Namespace, name and port of the service exposing the webhook.
+	ServiceNamespace string
+	ServiceName      string
+	ServicePort      int32
+	// Selectors restricting the namespaces and objects the webhook is called for (may be nil).
+	NamespaceSelector *metav1.LabelSelector
+	ObjectSelector    *metav1.LabelSelector
+	// Workload resources (of api group apps) the webhook is registered for; defaults to all supported workload resources.
+	WorkloadResources []string
+	// Failure policy for workloads; the webhook for pods always uses failure policy Ignore.
+	FailurePolicy admissionv1.FailurePolicyType
+	// Timeout for webhook calls.
+	TimeoutSeconds int32
+	// Whether to register the webhook for pod creation (required for pod eviction).
+	EnablePods bool
+	// Whether the webhook has side effects (required for immutable config).
+	EnableImmutableConfig bool
+}
+
+// BuildWebhookConfiguration returns the MutatingWebhookConfiguration for the given options (without ca bundle).
+func BuildWebhookConfiguration(options RegistrationOptions) *admissionv1.MutatingWebhookConfiguration {
+	sideEffects := admissionv1.SideEffectClassNone
+	if options.EnableImmutableConfig {
+		sideEffects = admissionv1.SideEffectClassNoneOnDryRun
+	}
+	// empty selectors are set explicitly, since the api server defaults them
+	namespaceSelector := &metav1.LabelSelector{}
+	if options.NamespaceSelector != nil {
+		namespaceSelector = options.NamespaceSelector
+	}
+	objectSelector := &metav1.LabelSelector{}
+	if options.ObjectSelector != nil {
+		objectSelector = options.ObjectSelector
+	}
+	workloadResources := SupportedWorkloadResources
+	if len(options.WorkloadResources) > 0 {
+		workloadResources = options.WorkloadResources
+	}
+	buildWebhook := func(name string, rule admissionv1.RuleWithOperations, failurePolicy admissionv1.FailurePolicyType, sideEffects admissionv1.SideEffectClass) admissionv1.MutatingWebhook {
+		return admissionv1.MutatingWebhook{
+			Name:                    name,
+			AdmissionReviewVersions: []string{"v1"},
+			ClientConfig: admissionv1.WebhookClientConfig{
+				Service: &admissionv1.ServiceReference{
+					Namespace: options.ServiceNamespace,
+					Name:      options.ServiceName,
+					Path:      &[]string{"/mutate"}[0],
+					Port:      &options.ServicePort,
+				},
+			},
+			Rules:              []admissionv1.RuleWithOperations{rule},
+			NamespaceSelector:  namespaceSelector.DeepCopy(),
+			ObjectSelector:     objectSelector.DeepCopy(),
+			MatchPolicy:        &[]admissionv1.MatchPolicyType{admissionv1.Equivalent}[0],
+			SideEffects:        &sideEffects,
+			TimeoutSeconds:     &options.TimeoutSeconds,
+			FailurePolicy:      &failurePolicy,
+			ReinvocationPolicy: &[]admissionv1.ReinvocationPolicyType{admissionv1.NeverReinvocationPolicy}[0],
+		}
+	}
+
+	webhookConfiguration := &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: options.Name,
+		},
+		Webhooks: []admissionv1.MutatingWebhook{
+			buildWebhook("mutate.apps.kubernetes", admissionv1.RuleWithOperations{
+				Operations: []admissionv1.OperationType{admissionv1.Create, admissionv1.Update},
+				Rule: admissionv1.Rule{
+					APIGroups:   []string{"apps"},
+					APIVersions: []string{"v1"},
+					Resources:   slices.Clone(workloadResources),
+					Scope:       &[]admissionv1.ScopeType{admissionv1.NamespacedScope}[0],
+				},
+			}, options.FailurePolicy, sideEffects),
+		},
+	}
+	if options.EnablePods {
+		webhookConfiguration.Webhooks = append(webhookConfiguration.Webhooks, buildWebhook("mutate.pods.kubernetes", admissionv1.RuleWithOperations{
+			Operations: []admissionv1.OperationType{admissionv1.Create},
+			Rule: admissionv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"pods"},
+				Scope:       &[]admissionv1.ScopeType{admissionv1.NamespacedScope}[0],
+			},
+		}, admissionv1.Ignore, admissionv1.SideEffectClassNone))
+	}
+	return webhookConfiguration
+}
+
+// RegisterWebhookConfiguration creates or updates the MutatingWebhookConfiguration according to the given options;
+// ca bundles of existing webhooks are preserved.
+func RegisterWebhookConfiguration(ctx context.Context, client ctrlclient.Client, options RegistrationOptions) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		desired := BuildWebhookConfiguration(options)
+		existing := &admissionv1.MutatingWebhookConfiguration{}
+		if err := client.Get(ctx, ctrlclient.ObjectKey{Name: options.Name}, existing); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			log.Info("creating webhook configuration", "name", options.Name)
+			return client.Create(ctx, desired)
+		}
+
+		caBundles := make(map[string][]byte)
+		for _, webhook := range existing.Webhooks {
+			caBundles[webhook.Name] = webhook.ClientConfig.CABundle
+		}
+		for i := range desired.Webhooks {
+			desired.Webhooks[i].ClientConfig.CABundle = caBundles[desired.Webhooks[i].Name]
+		}
+		if equality.Semantic.DeepEqual(existing.Webhooks, desired.Webhooks) {
+			return nil
+		}
+		log.Info("updating webhook configuration", "name", options.Name)
+		existing.Webhooks = desired.Webhooks
+		return client.Update(ctx, existing)
+	})
+}
+
+// SetupWebhookRegistrationWithManager adds a runnable (running on the leader only) to the given manager,
+// which periodically reconciles the webhook configuration.
+func SetupWebhookRegistrationWithManager(mgr ctrl.Manager, client ctrlclient.Client, options RegistrationOptions) error {
+	return mgr.Add(&registrar{client: client, options: options})
+}
+
+type registrar struct {
+	client  ctrlclient.Client
+	options RegistrationOptions
+}
+
+var _ manager.LeaderElectionRunnable = &registrar{}
+
+func (r *registrar) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *registrar) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("webhook-registrar")
+	ticker := time.NewTicker(registrationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := RegisterWebhookConfiguration(ctx, r.client, r.options); err != nil {
+			log.Error(err, "error reconciling webhook configuration")
+		}
+	}
+}
@@ -0,0 +1,155 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Test webhook registration", func() {
+	var options RegistrationOptions
+
+	BeforeEach(func() {
+		options = RegistrationOptions{
+			Name:             "pod-reloader-webhook",
+			ServiceNamespace: "pod-reloader",
+			ServiceName:      "pod-reloader-webhook",
+			ServicePort:      443,
+			FailurePolicy:    admissionv1.Fail,
+			TimeoutSeconds:   10,
+		}
+	})
+
+	webhookNames := func(webhookConfiguration *admissionv1.MutatingWebhookConfiguration) []string {
+		var names []string
+		for _, webhook := range webhookConfiguration.Webhooks {
+			names = append(names, webhook.Name)
+		}
+		return names
+	}
+
+	DescribeTable("building the webhook configuration",
+		func(modify func(options *RegistrationOptions), verify func(webhookConfiguration *admissionv1.MutatingWebhookConfiguration)) {
+			modify(&options)
+			webhookConfiguration := BuildWebhookConfiguration(options)
+			Expect(webhookConfiguration.Name).To(Equal("pod-reloader-webhook"))
+			Expect(webhookConfiguration.Webhooks).NotTo(BeEmpty())
+			for _, webhook := range webhookConfiguration.Webhooks {
+				Expect(webhook.ClientConfig.Service.Namespace).To(Equal("pod-reloader"))
+				Expect(webhook.ClientConfig.Service.Name).To(Equal("pod-reloader-webhook"))
+				Expect(*webhook.ClientConfig.Service.Port).To(Equal(int32(443)))
+				Expect(*webhook.ClientConfig.Service.Path).To(Equal("/mutate"))
+				Expect(*webhook.TimeoutSeconds).To(Equal(int32(10)))
+				Expect(webhook.ClientConfig.CABundle).To(BeEmpty())
+			}
+			verify(webhookConfiguration)
+		},
+		Entry("with defaults",
+			func(options *RegistrationOptions) {},
+			func(webhookConfiguration *admissionv1.MutatingWebhookConfiguration) {
+				Expect(webhookNames(webhookConfiguration)).To(Equal([]string{"mutate.apps.kubernetes"}))
+				webhook := webhookConfiguration.Webhooks[0]
+				Expect(webhook.Rules[0].Resources).To(Equal(SupportedWorkloadResources))
+				Expect(webhook.Rules[0].Operations).To(ConsistOf(admissionv1.Create, admissionv1.Update))
+				Expect(*webhook.FailurePolicy).To(Equal(admissionv1.Fail))
+				Expect(*webhook.SideEffects).To(Equal(admissionv1.SideEffectClassNone))
+				Expect(webhook.NamespaceSelector).To(Equal(&metav1.LabelSelector{}))
+				Expect(webhook.ObjectSelector).To(Equal(&metav1.LabelSelector{}))
+			},
+		),
+		Entry("with pods enabled",
+			func(options *RegistrationOptions) { options.EnablePods = true },
+			func(webhookConfiguration *admissionv1.MutatingWebhookConfiguration) {
+				Expect(webhookNames(webhookConfiguration)).To(Equal([]string{"mutate.apps.kubernetes", "mutate.pods.kubernetes"}))
+				webhook := webhookConfiguration.Webhooks[1]
+				Expect(webhook.Rules[0].Resources).To(Equal([]string{"pods"}))
+				Expect(webhook.Rules[0].Operations).To(ConsistOf(admissionv1.Create))
+				Expect(*webhook.FailurePolicy).To(Equal(admissionv1.Ignore))
+			},
+		),
+		Entry("with immutable config enabled",
+			func(options *RegistrationOptions) { options.EnablePods = true; options.EnableImmutableConfig = true },
+			func(webhookConfiguration *admissionv1.MutatingWebhookConfiguration) {
+				Expect(*webhookConfiguration.Webhooks[0].SideEffects).To(Equal(admissionv1.SideEffectClassNoneOnDryRun))
+				Expect(*webhookConfiguration.Webhooks[1].SideEffects).To(Equal(admissionv1.SideEffectClassNone))
+			},
+		),
+		Entry("with selectors, failure policy and workload resources",
+			func(options *RegistrationOptions) {
+				options.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+				options.ObjectSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "b"}}
+				options.FailurePolicy = admissionv1.Ignore
+				options.WorkloadResources = []string{"deployments"}
+			},
+			func(webhookConfiguration *admissionv1.MutatingWebhookConfiguration) {
+				webhook := webhookConfiguration.Webhooks[0]
+				Expect(webhook.NamespaceSelector.MatchLabels).To(Equal(map[string]string{"team": "a"}))
+				Expect(webhook.ObjectSelector.MatchLabels).To(Equal(map[string]string{"app": "b"}))
+				Expect(*webhook.FailurePolicy).To(Equal(admissionv1.Ignore))
+				Expect(webhook.Rules[0].Resources).To(Equal([]string{"deployments"}))
+			},
+		),
+	)
+
+	Context("registering the webhook configuration", func() {
+		var client ctrlclient.Client
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+			client = fakeclient.NewClientBuilder().WithScheme(scheme).Build()
+		})
+
+		getWebhookConfiguration := func() *admissionv1.MutatingWebhookConfiguration {
+			webhookConfiguration := &admissionv1.MutatingWebhookConfiguration{}
+			ExpectWithOffset(1, client.Get(ctx, ctrlclient.ObjectKey{Name: options.Name}, webhookConfiguration)).To(Succeed())
+			return webhookConfiguration
+		}
+
+		It("should create the webhook configuration, and keep it unchanged if up to date", func() {
+			Expect(RegisterWebhookConfiguration(ctx, client, options)).To(Succeed())
+			webhookConfiguration := getWebhookConfiguration()
+			Expect(webhookNames(webhookConfiguration)).To(Equal([]string{"mutate.apps.kubernetes"}))
+
+			Expect(RegisterWebhookConfiguration(ctx, client, options)).To(Succeed())
+			Expect(getWebhookConfiguration().ResourceVersion).To(Equal(webhookConfiguration.ResourceVersion))
+		})
+
+		It("should update the webhook configuration, preserving existing ca bundles", func() {
+			Expect(RegisterWebhookConfiguration(ctx, client, options)).To(Succeed())
+			webhookConfiguration := getWebhookConfiguration()
+			webhookConfiguration.Webhooks[0].ClientConfig.CABundle = []byte("ca")
+			Expect(client.Update(ctx, webhookConfiguration)).To(Succeed())
+
+			options.EnablePods = true
+			Expect(RegisterWebhookConfiguration(ctx, client, options)).To(Succeed())
+			webhookConfiguration = getWebhookConfiguration()
+			Expect(webhookNames(webhookConfiguration)).To(Equal([]string{"mutate.apps.kubernetes", "mutate.pods.kubernetes"}))
+			Expect(webhookConfiguration.Webhooks[0].ClientConfig.CABundle).To(Equal([]byte("ca")))
+			Expect(webhookConfiguration.Webhooks[1].ClientConfig.CABundle).To(BeEmpty())
+			resourceVersion := webhookConfiguration.ResourceVersion
+
+			// the preserved ca bundles do not count as changes
+			Expect(RegisterWebhookConfiguration(ctx, client, options)).To(Succeed())
+			Expect(getWebhookConfiguration().ResourceVersion).To(Equal(resourceVersion))
+
+			options.EnablePods = false
+			Expect(RegisterWebhookConfiguration(ctx, client, options)).To(Succeed())
+			webhookConfiguration = getWebhookConfiguration()
+			Expect(webhookNames(webhookConfiguration)).To(Equal([]string{"mutate.apps.kubernetes"}))
+			Expect(webhookConfiguration.Webhooks[0].ClientConfig.CABundle).To(Equal([]byte("ca")))
+		})
+	})
+})
@@ -0,0 +1,232 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+const snapshotSuffixLength = 10
+
+// snapshotIndex maps kind and name of the snapshots in a namespace to the name of the object they were copied from
+type snapshotIndex map[string]map[string]string
+
+// list the snapshots in the given namespace; snapshots are identified by the label and annotation set upon their creation (instead of their names),
+// so objects which just happen to be named like a snapshot are never treated as such
+func (m *mutator) listSnapshots(ctx context.Context, namespace string) (snapshotIndex, error) {
+	snapshots := snapshotIndex{
+		reloader.KindConfigMap: {},
+		reloader.KindSecret:    {},
+	}
+	configMapList := &corev1.ConfigMapList{}
+	if err := m.client.List(ctx, configMapList, ctrlclient.InNamespace(namespace), ctrlclient.MatchingLabels{reloader.LabelSnapshot: "true"}); err != nil {
+		return nil, err
+	}
+	for _, configMap := range configMapList.Items {
+		snapshots[reloader.KindConfigMap][configMap.Name] = configMap.Annotations[reloader.AnnotationSnapshotOf]
+	}
+	secretList := &corev1.SecretList{}
+	if err := m.client.List(ctx, secretList, ctrlclient.InNamespace(namespace), ctrlclient.MatchingLabels{reloader.LabelSnapshot: "true"}); err != nil {
+		return nil, err
+	}
+	for _, secret := range secretList.Items {
+		snapshots[reloader.KindSecret][secret.Name] = secret.Annotations[reloader.AnnotationSnapshotOf]
+	}
+	return snapshots, nil
+}
+
+// check if the given reference (of the given kind) refers to the original object, or to one of its snapshots
+func (s snapshotIndex) isSnapshotOf(kind string, reference string, original string) bool {
+	if reference == original {
+		return true
+	}
+	snapshotOf, ok := s[kind][reference]
+	return ok && snapshotOf == original
+}
+
+// create immutable copies of the config maps and secrets referenced by the given workload, and let the pod template refer to these copies;
+// copies are named by their content hash, so they are shared by all workloads of the namespace referencing the same content; references
+// to other snapshots of the same objects are only replaced if replaceSnapshots is set (i.e. if the controller requested a reload)
+func (m *mutator) snapshotReferences(ctx context.Context, gvk schema.GroupVersionKind, objMeta *metav1.ObjectMeta, podTemplate *corev1.PodTemplateSpec, existingSnapshots snapshotIndex, replaceSnapshots bool, dryRun bool) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	snapshots := map[string]map[string]string{
+		reloader.KindConfigMap: {},
+		reloader.KindSecret:    {},
+	}
+
+	for _, name := range reloader.SplitNames(objMeta.Annotations[reloader.AnnotationConfigMaps]) {
+		configMap := &corev1.ConfigMap{}
+		if err := m.client.Get(ctx, ctrlclient.ObjectKey{Namespace: objMeta.Namespace, Name: name}, configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		snapshot := &corev1.ConfigMap{
+			ObjectMeta: buildSnapshotMeta(gvk, objMeta, name, reloader.ConfigMapContentHash(configMap)),
+			Immutable:  &[]bool{true}[0],
+			Data:       configMap.Data,
+			BinaryData: configMap.BinaryData,
+		}
+		if !dryRun {
+			if err := m.ensureSnapshot(ctx, snapshot, &corev1.ConfigMap{}); err != nil {
+				return err
+			}
+		}
+		snapshots[reloader.KindConfigMap][name] = snapshot.Name
+	}
+
+	for _, name := range reloader.SplitNames(objMeta.Annotations[reloader.AnnotationSecrets]) {
+		secret := &corev1.Secret{}
+		if err := m.client.Get(ctx, ctrlclient.ObjectKey{Namespace: objMeta.Namespace, Name: name}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		snapshot := &corev1.Secret{
+			ObjectMeta: buildSnapshotMeta(gvk, objMeta, name, reloader.SecretContentHash(secret)),
+			Immutable:  &[]bool{true}[0],
+			Type:       secret.Type,
+			Data:       secret.Data,
+		}
+		if !dryRun {
+			if err := m.ensureSnapshot(ctx, snapshot, &corev1.Secret{}); err != nil {
+				return err
+			}
+		}
+		snapshots[reloader.KindSecret][name] = snapshot.Name
+	}
+
+	reloader.VisitReferences(&podTemplate.Spec, func(kind string, name *string) {
+		for original, snapshotName := range snapshots[kind] {
+			if (*name == original || replaceSnapshots && existingSnapshots.isSnapshotOf(kind, *name, original)) && *name != snapshotName {
+				log.V(1).Info("replacing reference", "kind", kind, "from", *name, "to", snapshotName)
+				*name = snapshotName
+			}
+		}
+	})
+
+	return nil
+}
+
+// let the given pod template refer to the same snapshots as the old pod template, for all config maps and secrets referenced by the workload
+func keepSnapshotReferences(objMeta *metav1.ObjectMeta, oldPodTemplate *corev1.PodTemplateSpec, podTemplate *corev1.PodTemplateSpec, existingSnapshots snapshotIndex) {
+	originals := map[string][]string{
+		reloader.KindConfigMap: reloader.SplitNames(objMeta.Annotations[reloader.AnnotationConfigMaps]),
+		reloader.KindSecret:    reloader.SplitNames(objMeta.Annotations[reloader.AnnotationSecrets]),
+	}
+	snapshots := map[string]map[string]string{
+		reloader.KindConfigMap: {},
+		reloader.KindSecret:    {},
+	}
+	reloader.VisitReferences(&oldPodTemplate.Spec, func(kind string, name *string) {
+		for _, original := range originals[kind] {
+			if *name != original && existingSnapshots.isSnapshotOf(kind, *name, original) {
+				snapshots[kind][original] = *name
+			}
+		}
+	})
+	reloader.VisitReferences(&podTemplate.Spec, func(kind string, name *string) {
+		for original, snapshotName := range snapshots[kind] {
+			if existingSnapshots.isSnapshotOf(kind, *name, original) {
+				*name = snapshotName
+			}
+		}
+	})
+}
+
+// check if the given pod template refers to snapshots of the config maps and secrets referenced by the workload, but not to the originals
+func refersToSnapshotsOnly(objMeta *metav1.ObjectMeta, podTemplate *corev1.PodTemplateSpec, existingSnapshots snapshotIndex) bool {
+	originals := map[string][]string{
+		reloader.KindConfigMap: reloader.SplitNames(objMeta.Annotations[reloader.AnnotationConfigMaps]),
+		reloader.KindSecret:    reloader.SplitNames(objMeta.Annotations[reloader.AnnotationSecrets]),
+	}
+	snapshots := false
+	originalsFound := false
+	reloader.VisitReferences(&podTemplate.Spec, func(kind string, name *string) {
+		for _, original := range originals[kind] {
+			if *name == original {
+				originalsFound = true
+			} else if existingSnapshots.isSnapshotOf(kind, *name, original) {
+				snapshots = true
+			}
+		}
+	})
+	return snapshots && !originalsFound
+}
+
+// create the given snapshot object if not existing; if it exists, ensure that it is owned by the snapshot's owner;
+// existing snapshots are looked up in the cache first, so the api server is only called if something has to change
+func (m *mutator) ensureSnapshot(ctx context.Context, snapshot ctrlclient.Object, existing ctrlclient.Object) error {
+	if err := m.client.Get(ctx, ctrlclient.ObjectKeyFromObject(snapshot), existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err := m.client.Create(ctx, snapshot); !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		// created concurrently, but not yet in the cache; missing owner references are added by the controller
+		return nil
+	}
+	if len(snapshot.GetOwnerReferences()) == 0 {
+		return nil
+	}
+	if existing.GetLabels()[reloader.LabelSnapshot] != "true" {
+		// should not happen, unless someone created an object with a colliding name
+		return nil
+	}
+	ownerReferences := existing.GetOwnerReferences()
+	for _, ownerReference := range ownerReferences {
+		if ownerReference.UID == snapshot.GetOwnerReferences()[0].UID {
+			return nil
+		}
+	}
+	existing.SetOwnerReferences(append(ownerReferences, snapshot.GetOwnerReferences()[0]))
+	return m.client.Update(ctx, existing)
+}
+
+func buildSnapshotMeta(gvk schema.GroupVersionKind, objMeta *metav1.ObjectMeta, name string, hash string) metav1.ObjectMeta {
+	snapshotMeta := metav1.ObjectMeta{
+		Namespace: objMeta.Namespace,
+		Name:      snapshotName(name, hash),
+		Labels: map[string]string{
+			reloader.LabelSnapshot: "true",
+		},
+		Annotations: map[string]string{
+			reloader.AnnotationSnapshotOf: name,
+		},
+	}
+	// on creation of the workload, its uid is not yet known; in that case the owner reference is added by the controller (or on the next update)
+	if objMeta.UID != "" {
+		apiVersion, kind := gvk.ToAPIVersionAndKind()
+		snapshotMeta.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       objMeta.Name,
+			UID:        objMeta.UID,
+		}}
+	}
+	return snapshotMeta
+}
+
+func snapshotName(name string, hash string) string {
+	if maxLength := 253 - 1 - snapshotSuffixLength; len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], ".-")
+	}
+	return name + "-" + hash[:snapshotSuffixLength]
+}
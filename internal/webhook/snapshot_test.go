@@ -0,0 +1,168 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/sap/pod-reloader/internal/reloader"
+)
+
+var _ = Describe("Test immutable configuration snapshots", func() {
+	var configMap *corev1.ConfigMap
+	var creates int
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config"},
+			Data:       map[string]string{"key": "value"},
+		}
+		creates = 0
+	})
+
+	newSnapshotMutator := func() *mutator {
+		m := newTestMutator(Options{EnableImmutableConfig: true}, configMap)
+		m.client = interceptor.NewClient(m.client.(ctrlclient.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, client ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.CreateOption) error {
+				creates++
+				return client.Create(ctx, obj, opts...)
+			},
+		})
+		return m
+	}
+
+	snapshot := func(m *mutator, uid types.UID) (*corev1.ConfigMap, *corev1.PodTemplateSpec) {
+		deployment := buildDeployment("test", "test", map[string]string{
+			reloader.AnnotationConfigMaps:      "config",
+			reloader.AnnotationImmutableConfig: "true",
+		})
+		deployment.UID = uid
+		deployment.Spec.Template.Spec.Volumes = []corev1.Volume{{
+			Name:         "config",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}}},
+		}}
+		gvk := appsv1.SchemeGroupVersion.WithKind("Deployment")
+		ExpectWithOffset(1, m.snapshotReferences(ctx, gvk, &deployment.ObjectMeta, &deployment.Spec.Template, nil, true, false)).To(Succeed())
+		name := deployment.Spec.Template.Spec.Volumes[0].ConfigMap.Name
+		snapshot := &corev1.ConfigMap{}
+		ExpectWithOffset(1, m.client.Get(ctx, ctrlclient.ObjectKey{Namespace: "test", Name: name}, snapshot)).To(Succeed())
+		return snapshot, &deployment.Spec.Template
+	}
+
+	It("should create snapshots, and let the pod template refer to them", func() {
+		m := newSnapshotMutator()
+		s, _ := snapshot(m, "")
+		Expect(s.Name).To(MatchRegexp(`^config-[0-9a-f]{10}$`))
+		Expect(*s.Immutable).To(BeTrue())
+		Expect(s.Data).To(Equal(configMap.Data))
+		Expect(s.Labels).To(HaveKeyWithValue(reloader.LabelSnapshot, "true"))
+		Expect(s.Annotations).To(HaveKeyWithValue(reloader.AnnotationSnapshotOf, "config"))
+		// the uid of workloads is not known on creation
+		Expect(s.OwnerReferences).To(BeEmpty())
+		Expect(creates).To(Equal(1))
+	})
+
+	It("should not try to create existing snapshots again", func() {
+		m := newSnapshotMutator()
+		snapshot(m, "")
+		snapshot(m, "")
+		Expect(creates).To(Equal(1))
+	})
+
+	It("should add owner references to existing snapshots", func() {
+		m := newSnapshotMutator()
+		snapshot(m, "")
+		s, _ := snapshot(m, "uid1")
+		Expect(s.OwnerReferences).To(HaveLen(1))
+		Expect(s.OwnerReferences[0].UID).To(Equal(types.UID("uid1")))
+		s, _ = snapshot(m, "uid2")
+		Expect(s.OwnerReferences).To(HaveLen(2))
+		s, _ = snapshot(m, "uid2")
+		Expect(s.OwnerReferences).To(HaveLen(2))
+	})
+
+	It("should create new snapshots when the content changes", func() {
+		m := newSnapshotMutator()
+		s1, _ := snapshot(m, "")
+		configMap.Data["key"] = "other"
+		Expect(m.client.Update(ctx, configMap)).To(Succeed())
+		s2, _ := snapshot(m, "")
+		Expect(s2.Name).NotTo(Equal(s1.Name))
+		Expect(creates).To(Equal(2))
+	})
+
+	It("should keep the snapshot references of the old pod template", func() {
+		m := newSnapshotMutator()
+		_, oldPodTemplate := snapshot(m, "")
+		objMeta := &metav1.ObjectMeta{Annotations: map[string]string{reloader.AnnotationConfigMaps: "config"}}
+		podTemplate := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         "config",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}}},
+		}}}}
+		existingSnapshots, err := m.listSnapshots(ctx, "test")
+		Expect(err).NotTo(HaveOccurred())
+		keepSnapshotReferences(objMeta, oldPodTemplate, podTemplate, existingSnapshots)
+		Expect(podTemplate.Spec.Volumes[0].ConfigMap.Name).To(Equal(oldPodTemplate.Spec.Volumes[0].ConfigMap.Name))
+	})
+
+	It("should keep the snapshot references and the hash of a previous pod template on rollout undo", func() {
+		m := newSnapshotMutator()
+		gvk := appsv1.SchemeGroupVersion.WithKind("Deployment")
+		deployment := buildDeployment("test", "test", map[string]string{
+			reloader.AnnotationConfigMaps:      "config",
+			reloader.AnnotationImmutableConfig: "true",
+		})
+		deployment.Spec.Template.Spec.Volumes = []corev1.Volume{{
+			Name:         "config",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}}},
+		}}
+		Expect(m.handleCreateOrUpdate(ctx, gvk, deployment, nil, false)).To(Succeed())
+		previousTemplate := deployment.Spec.Template.DeepCopy()
+		Expect(previousTemplate.Spec.Volumes[0].ConfigMap.Name).To(MatchRegexp(`^config-[0-9a-f]{10}$`))
+
+		// reload triggered by the controller after a change of the config map
+		configMap.Data["key"] = "changed"
+		Expect(m.client.Update(ctx, configMap)).To(Succeed())
+		oldDeployment := deployment.DeepCopy()
+		hash, err := reloader.GenerateHashForObject(ctx, m.client, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		reloader.InjectHash(deployment, hash)
+		Expect(m.handleCreateOrUpdate(ctx, gvk, deployment, oldDeployment, false)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).To(Equal(hash))
+		Expect(deployment.Spec.Template.Spec.Volumes[0].ConfigMap.Name).To(MatchRegexp(`^config-[0-9a-f]{10}$`))
+		Expect(deployment.Spec.Template.Spec.Volumes[0].ConfigMap.Name).NotTo(Equal(previousTemplate.Spec.Volumes[0].ConfigMap.Name))
+
+		// kubectl rollout undo restores the previous pod template
+		oldDeployment = deployment.DeepCopy()
+		deployment.Spec.Template = *previousTemplate.DeepCopy()
+		Expect(m.handleCreateOrUpdate(ctx, gvk, deployment, oldDeployment, false)).To(Succeed())
+		Expect(deployment.Spec.Template).To(Equal(*previousTemplate))
+	})
+
+	It("should identify snapshots by their label and annotation", func() {
+		m := newSnapshotMutator()
+		s, _ := snapshot(m, "")
+		// named like a snapshot, but not created by the webhook
+		Expect(m.client.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "config-0123456789"}})).To(Succeed())
+		existingSnapshots, err := m.listSnapshots(ctx, "test")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(existingSnapshots.isSnapshotOf(reloader.KindConfigMap, "config", "config")).To(BeTrue())
+		Expect(existingSnapshots.isSnapshotOf(reloader.KindConfigMap, s.Name, "config")).To(BeTrue())
+		Expect(existingSnapshots.isSnapshotOf(reloader.KindConfigMap, "config-0123456789", "config")).To(BeFalse())
+		Expect(existingSnapshots.isSnapshotOf(reloader.KindConfigMap, s.Name, "other")).To(BeFalse())
+		Expect(existingSnapshots.isSnapshotOf(reloader.KindSecret, s.Name, "config")).To(BeFalse())
+	})
+})
@@ -9,11 +9,30 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/sap/pod-reloader/internal/reloader"
 )
 
-func SetupMutatingWebhookWithManager(mgr ctrl.Manager) {
+// Options controls the behavior of the webhook.
+type Options struct {
+	// Whether to create immutable copies of referenced config maps and secrets for workloads annotated
+	// with pod-reloader.cs.sap.com/immutable-config; note that this makes the webhook have side effects (except for dry-run requests).
+	EnableImmutableConfig bool
+	// If set, the configuration hash on the pod template is maintained by the controller through server-side apply
+	// with this field manager; in that case, the webhook sets the hash upon creation only, and validates changes on updates.
+	HashFieldManager string
+	// Namespaces the webhook operates in; requests for other namespaces are passed through unchanged.
+	NamespaceScope reloader.NamespaceScope
+}
+
+func SetupMutatingWebhookWithManager(mgr ctrl.Manager, options Options) error {
 	scheme := mgr.GetScheme()
 	client := mgr.GetClient()
 	decoder := admission.NewDecoder(scheme)
-	mgr.GetWebhookServer().Register("/mutate", &webhook.Admission{Handler: &mutator{scheme: scheme, client: client, decoder: decoder}})
+	cache := newHashCache()
+	if err := cache.setupWithManager(mgr); err != nil {
+		return err
+	}
+	mgr.GetWebhookServer().Register("/mutate", &webhook.Admission{Handler: &mutator{scheme: scheme, client: client, decoder: decoder, cache: cache, options: options}})
+	return nil
 }
@@ -6,23 +6,38 @@ SPDX-License-Identifier: Apache-2.0
 package main
 
 import (
+	"context"
 	"flag"
 	"net"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	podreloaderv1alpha1 "github.com/sap/pod-reloader/api/v1alpha1"
+	"github.com/sap/pod-reloader/internal/certs"
 	"github.com/sap/pod-reloader/internal/controller"
+	"github.com/sap/pod-reloader/internal/health"
+	"github.com/sap/pod-reloader/internal/reloader"
 	"github.com/sap/pod-reloader/internal/webhook"
 )
 
@@ -41,6 +56,7 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(podreloaderv1alpha1.AddToScheme(scheme))
 }
 
 func main() {
@@ -50,12 +66,94 @@ func main() {
 	var webhookCertDir string
 	var enableLeaderElection bool
 	var leaderElectionNamespace string
+	var minReloadInterval time.Duration
+	var maxConcurrentRollouts int
+	var waitForRollout bool
+	var enableRollback bool
+	var rollbackTimeout time.Duration
+	var enableImmutableConfig bool
+	var volumeSyncDelay time.Duration
+	var reloadViaAPIServerProxy bool
+	var reloadTimeout time.Duration
+	var reloadRetries int
+	var reloadCAFile string
+	var reloadInsecureSkipVerify bool
+	var enablePodEviction bool
+	var podEvictionInterval time.Duration
+	var enableStagedReloads bool
+	var reloadBatchSize int
+	var reloadWaveTimeout time.Duration
+	var enableReloadStatus bool
+	var hashFieldManager string
+	var enableWebhookCertManagement bool
+	var webhookCertNamespace string
+	var webhookCertSecret string
+	var webhookService string
+	var webhookConfigurationName string
+	var enableWebhookRegistration bool
+	var watchNamespaces string
+	var enableSharding bool
+	var enableSecretProviderClasses bool
+	var enableResourceReferences bool
+	var enableImplicitSecrets bool
+	var enableImageDigestTracking bool
+	var imageDigestInterval time.Duration
+	var registryTimeout time.Duration
+	var shardIdentity string
+	var namespaceSelector string
+	var webhookServicePort int
+	var webhookNamespaceSelector string
+	var webhookObjectSelector string
+	var webhookWorkloadResources string
+	var webhookFailurePolicy string
+	var webhookTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&webhookAddr, "webhook-bind-address", ":9443", "The address the webhook endpoint binds to.")
 	flag.StringVar(&webhookCertDir, "webhook-tls-directory", "", "The directory containing tls server key and certificate, as tls.key and tls.crt; defaults to $TMPDIR/k8s-webhook-server/serving-certs")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "", "The namespace to use for the leader election lock; defaults to controller namespace when running in-cluster.")
+	flag.DurationVar(&minReloadInterval, "min-reload-interval", 0, "Default minimum interval between two reloads of the same workload triggered by config map or secret changes; may be overridden per workload by annotation.")
+	flag.IntVar(&maxConcurrentRollouts, "max-concurrent-rollouts", 0, "Maximum number of rollouts triggered by config map or secret changes which may be in progress at the same time; 0 means unlimited.")
+	flag.BoolVar(&waitForRollout, "wait-for-rollout", false, "Wait for the current rollout of a workload to complete before triggering another reload; may be overridden per workload by annotation.")
+	flag.BoolVar(&enableRollback, "enable-rollback", false, "Enable automatic rollback of configuration for workloads annotated accordingly, if a rollout caused by a configuration change fails.")
+	flag.DurationVar(&rollbackTimeout, "rollback-timeout", 10*time.Minute, "Default time after which a rollout caused by a configuration change is considered as failed; may be overridden per workload by annotation.")
+	flag.BoolVar(&enableImmutableConfig, "enable-immutable-config", false, "Enable immutable copies of referenced config maps and secrets for workloads annotated accordingly; requires the webhook to be registered with sideEffects NoneOnDryRun.")
+	flag.DurationVar(&volumeSyncDelay, "volume-sync-delay", 2*time.Minute, "Time to wait after a configuration change before reloading workloads in-place, such that kubelet had the chance to sync mounted volumes.")
+	flag.BoolVar(&reloadViaAPIServerProxy, "reload-via-apiserver-proxy", false, "Call http reload endpoints through the api server's pods/proxy subresource instead of directly through the pod ip.")
+	flag.DurationVar(&reloadTimeout, "reload-timeout", 10*time.Second, "Timeout for a single call of an http reload endpoint.")
+	flag.IntVar(&reloadRetries, "reload-retries", 2, "Number of retries when calling an http reload endpoint.")
+	flag.StringVar(&reloadCAFile, "reload-ca-file", "", "File containing ca certificates to verify https reload endpoints against, when calling them directly; defaults to the system roots.")
+	flag.BoolVar(&reloadInsecureSkipVerify, "reload-insecure-skip-verify", false, "Skip the verification of the certificates of https reload endpoints, when calling them directly.")
+	flag.BoolVar(&enablePodEviction, "enable-pod-eviction", false, "Evict pods which are directly annotated with references to config maps or secrets, if these change; requires the webhook to be registered for pod creation.")
+	flag.DurationVar(&podEvictionInterval, "pod-eviction-interval", 30*time.Second, "Default minimum interval between two pod evictions in the same namespace; may be overridden per pod by annotation.")
+	flag.BoolVar(&enableStagedReloads, "enable-staged-reloads", false, "Reload workloads referencing the same config map or secret in waves, starting with canaries, instead of all at once.")
+	flag.IntVar(&reloadBatchSize, "reload-batch-size", 5, "Maximum number of workloads reloaded per wave, if staged reloads are enabled; 0 means unlimited.")
+	flag.DurationVar(&reloadWaveTimeout, "reload-wave-timeout", 10*time.Minute, "Time after the start of a reload wave after which its incomplete rollouts are considered as failed, causing the staged reload to be aborted.")
+	flag.BoolVar(&enableReloadStatus, "enable-reload-status", false, "Maintain a ReloadStatus object per namespace, reporting the reload state of tracked workloads; requires the according custom resource definition.")
+	flag.StringVar(&hashFieldManager, "hash-field-manager", "", "If set, the configuration hash on pod templates is updated by the controller through server-side apply with this field manager, instead of by the webhook.")
+	flag.BoolVar(&enableWebhookCertManagement, "enable-webhook-cert-management", false, "Generate and rotate a self-signed ca and webhook serving certificate, and maintain the ca bundle of the webhook configuration.")
+	flag.StringVar(&webhookCertNamespace, "webhook-cert-namespace", "", "The namespace of the secret holding the webhook certificates, and of the webhook service; defaults to controller namespace when running in-cluster.")
+	flag.StringVar(&webhookCertSecret, "webhook-cert-secret", "pod-reloader-webhook-tls", "The name of the secret holding the webhook certificates.")
+	flag.StringVar(&webhookService, "webhook-service", "pod-reloader-webhook", "The name of the service exposing the webhook, used to derive the dns names of the serving certificate.")
+	flag.StringVar(&webhookConfigurationName, "webhook-configuration-name", "pod-reloader-webhook", "The name of the mutating webhook configuration.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "Comma-separated list of namespaces to operate in (only these namespaces are watched, allowing to run with namespaced permissions); defaults to all namespaces.")
+	flag.StringVar(&namespaceSelector, "namespace-selector", "", "Label selector restricting the namespaces to operate in; requires permissions to watch namespaces. Unlike --watch-namespaces, this does not restrict the watches (objects in all namespaces are still cached).")
+	flag.BoolVar(&enableSecretProviderClasses, "enable-secret-provider-classes", false, "Reload workloads referencing secret provider classes upon rotations reported by the secrets store csi driver; requires the driver's custom resource definitions.")
+	flag.BoolVar(&enableResourceReferences, "enable-resource-references", false, "Reload workloads referencing arbitrary resources (through group/version/kind:name references) upon changes of these resources; requires permissions to watch the referenced resource types.")
+	flag.BoolVar(&enableImplicitSecrets, "enable-implicit-secrets", false, "Reload workloads annotated accordingly upon changes of their image pull secrets, their service account, or the secrets referenced by the service account; requires permissions to watch service accounts.")
+	flag.BoolVar(&enableImageDigestTracking, "enable-image-digest-tracking", false, "Periodically resolve the image digests of workloads annotated accordingly, and restart them if a digest changes.")
+	flag.DurationVar(&imageDigestInterval, "image-digest-interval", 5*time.Minute, "Interval in which image digests are resolved, if image digest tracking is enabled.")
+	flag.DurationVar(&registryTimeout, "registry-timeout", 10*time.Second, "Timeout for a single registry request when resolving image digests.")
+	flag.BoolVar(&enableSharding, "enable-sharding", false, "Handle config map and secret changes on all replicas, splitting the namespaces between them; replicas are coordinated through leases in the leader election namespace.")
+	flag.StringVar(&shardIdentity, "shard-identity", "", "The identity of this replica when sharding is enabled; defaults to the host name.")
+	flag.BoolVar(&enableWebhookRegistration, "enable-webhook-registration", false, "Create the mutating webhook configuration at startup, and keep it in sync with the enabled features.")
+	flag.IntVar(&webhookServicePort, "webhook-service-port", 443, "The port of the service exposing the webhook, used when registering the webhook.")
+	flag.StringVar(&webhookNamespaceSelector, "webhook-namespace-selector", "", "Label selector restricting the namespaces the webhook is called for, used when registering the webhook; defaults to the namespaces given by --watch-namespaces and --namespace-selector.")
+	flag.StringVar(&webhookObjectSelector, "webhook-object-selector", "pod-reloader.cs.sap.com/ignored notin (true),pod-reloader.cs.sap.com/disabled notin (true)", "Label selector restricting the objects the webhook is called for, used when registering the webhook.")
+	flag.StringVar(&webhookWorkloadResources, "webhook-workload-resources", strings.Join(webhook.SupportedWorkloadResources, ","), "Comma-separated list of workload resources (of api group apps) the webhook is called for, used when registering the webhook.")
+	flag.StringVar(&webhookFailurePolicy, "webhook-failure-policy", string(admissionv1.Fail), "The failure policy (Fail or Ignore) of the webhook for workloads, used when registering the webhook.")
+	flag.DurationVar(&webhookTimeout, "webhook-timeout", 10*time.Second, "The timeout of webhook calls (between 1s and 30s), used when registering the webhook.")
 	opts := zap.Options{
 		Development: false,
 	}
@@ -76,7 +174,17 @@ func main() {
 		os.Exit(1)
 	}
 
-	if enableLeaderElection && leaderElectionNamespace == "" {
+	if enableImageDigestTracking && imageDigestInterval <= 0 {
+		setupLog.Error(nil, "invalid command line parameter (must be positive)", "flag", "--image-digest-interval")
+		os.Exit(1)
+	}
+
+	if hashFieldManager != "" && enableImmutableConfig {
+		setupLog.Error(nil, "command line parameters are mutually exclusive", "flags", []string{"--hash-field-manager", "--enable-immutable-config"})
+		os.Exit(1)
+	}
+
+	if (enableLeaderElection || enableSharding) && leaderElectionNamespace == "" {
 		if inCluster {
 			leaderElectionNamespace = inClusterNamespace
 		} else {
@@ -85,7 +193,68 @@ func main() {
 		}
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	if enableSharding && shardIdentity == "" {
+		if shardIdentity, err = os.Hostname(); err != nil {
+			setupLog.Error(err, "unable to determine host name")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhookCertManagement || enableWebhookRegistration {
+		if webhookCertNamespace == "" {
+			if inCluster {
+				webhookCertNamespace = inClusterNamespace
+			} else {
+				setupLog.Error(nil, "missing command line parameter", "flag", "--webhook-cert-namespace")
+				os.Exit(1)
+			}
+		}
+	}
+
+	if enableWebhookCertManagement {
+		if webhookCertDir == "" {
+			webhookCertDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+		}
+	}
+
+	var namespaceScope reloader.NamespaceScope
+	if watchNamespaces != "" {
+		if namespaceScope.Namespaces, err = parseNamespaces(watchNamespaces); err != nil {
+			setupLog.Error(err, "invalid namespaces")
+			os.Exit(1)
+		}
+	}
+	if namespaceSelector != "" {
+		if namespaceScope.Selector, err = labels.Parse(namespaceSelector); err != nil {
+			setupLog.Error(err, "invalid namespace selector")
+			os.Exit(1)
+		}
+	}
+
+	var webhookRegistrationOptions webhook.RegistrationOptions
+	if enableWebhookRegistration {
+		if webhookNamespaceSelector == "" {
+			// by default, register the webhook for the namespaces in scope only
+			var requirements []string
+			if watchNamespaces != "" {
+				requirements = append(requirements, corev1.LabelMetadataName+" in ("+strings.Join(namespaceScope.Namespaces, ",")+")")
+			}
+			if namespaceSelector != "" {
+				requirements = append(requirements, namespaceSelector)
+			}
+			webhookNamespaceSelector = strings.Join(requirements, ",")
+		}
+		webhookRegistrationOptions, err = buildWebhookRegistrationOptions(webhookConfigurationName, webhookCertNamespace, webhookService, webhookServicePort,
+			webhookNamespaceSelector, webhookObjectSelector, webhookWorkloadResources, webhookFailurePolicy, webhookTimeout, enablePodEviction, enableImmutableConfig)
+		if err != nil {
+			setupLog.Error(err, "invalid webhook registration parameters")
+			os.Exit(1)
+		}
+	}
+
+	cfg := ctrl.GetConfigOrDie()
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:                        scheme,
 		LeaderElection:                enableLeaderElection,
 		LeaderElectionNamespace:       leaderElectionNamespace,
@@ -100,24 +269,124 @@ func main() {
 			BindAddress: metricsAddr,
 		},
 		HealthProbeBindAddress: probeAddr,
+		Cache:                  buildCacheOptions(namespaceScope),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	if err := controller.SetupControllerWithManager(mgr); err != nil {
+	// the manager's client is not usable before the manager is started, so an uncached client is used
+	client, err := ctrlclient.New(cfg, ctrlclient.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		os.Exit(1)
+	}
+
+	if enableWebhookRegistration {
+		setupLog.Info("registering webhook configuration")
+		if err := webhook.RegisterWebhookConfiguration(ctrl.LoggerInto(context.Background(), setupLog), client, webhookRegistrationOptions); err != nil {
+			setupLog.Error(err, "unable to register webhook configuration")
+			os.Exit(1)
+		}
+		if err := webhook.SetupWebhookRegistrationWithManager(mgr, client, webhookRegistrationOptions); err != nil {
+			setupLog.Error(err, "unable to set up webhook registration")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhookCertManagement {
+		certManager := certs.NewManager(client, certs.Options{
+			Namespace:                webhookCertNamespace,
+			SecretName:               webhookCertSecret,
+			WebhookConfigurationName: webhookConfigurationName,
+			DNSNames: []string{
+				webhookService,
+				webhookService + "." + webhookCertNamespace,
+				webhookService + "." + webhookCertNamespace + ".svc",
+				webhookService + "." + webhookCertNamespace + ".svc.cluster.local",
+			},
+			CertDir: webhookCertDir,
+		})
+		setupLog.Info("bootstrapping webhook certificates")
+		if err := certManager.Bootstrap(ctrl.LoggerInto(context.Background(), setupLog)); err != nil {
+			setupLog.Error(err, "unable to bootstrap webhook certificates")
+			os.Exit(1)
+		}
+		if err := certManager.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up webhook certificate management")
+			os.Exit(1)
+		}
+	}
+
+	progress := health.NewProgress()
+	if err := controller.SetupControllerWithManager(mgr, controller.Options{
+		MinReloadInterval:           minReloadInterval,
+		MaxConcurrentRollouts:       maxConcurrentRollouts,
+		WaitForRollout:              waitForRollout,
+		EnableRollback:              enableRollback,
+		RollbackTimeout:             rollbackTimeout,
+		EnableImmutableConfig:       enableImmutableConfig,
+		VolumeSyncDelay:             volumeSyncDelay,
+		ReloadViaAPIServerProxy:     reloadViaAPIServerProxy,
+		ReloadTimeout:               reloadTimeout,
+		ReloadRetries:               reloadRetries,
+		ReloadCAFile:                reloadCAFile,
+		ReloadInsecureSkipVerify:    reloadInsecureSkipVerify,
+		EnablePodEviction:           enablePodEviction,
+		PodEvictionInterval:         podEvictionInterval,
+		EnableStagedReloads:         enableStagedReloads,
+		ReloadBatchSize:             reloadBatchSize,
+		ReloadWaveTimeout:           reloadWaveTimeout,
+		EnableReloadStatus:          enableReloadStatus,
+		HashFieldManager:            hashFieldManager,
+		NamespaceScope:              namespaceScope,
+		EnableSecretProviderClasses: enableSecretProviderClasses,
+		EnableResourceReferences:    enableResourceReferences,
+		EnableImplicitSecrets:       enableImplicitSecrets,
+		EnableImageDigestTracking:   enableImageDigestTracking,
+		ImageDigestInterval:         imageDigestInterval,
+		RegistryTimeout:             registryTimeout,
+		EnableSharding:              enableSharding,
+		ShardLeaseNamespace:         leaderElectionNamespace,
+		ShardIdentity:               shardIdentity,
+		NewQueue:                    progress.NewQueue,
+	}); err != nil {
 		setupLog.Error(err, "unable to set up controller")
 		os.Exit(1)
 	}
 
-	webhook.SetupMutatingWebhookWithManager(mgr)
+	if err := webhook.SetupMutatingWebhookWithManager(mgr, webhook.Options{
+		EnableImmutableConfig: enableImmutableConfig,
+		HashFieldManager:      hashFieldManager,
+		NamespaceScope:        namespaceScope,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up webhook")
+		os.Exit(1)
+	}
 
+	heartbeat := health.NewHeartbeat(mgr.GetCache())
+	if err := heartbeat.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up heartbeat")
+		os.Exit(1)
+	}
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	if err := mgr.AddHealthzCheck("heartbeat", heartbeat.Checker()); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddHealthzCheck("progress", progress.Checker()); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("cache", health.CacheSyncChecker(mgr.GetCache())); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
@@ -129,6 +398,35 @@ func main() {
 	}
 }
 
+// parse a comma-separated list of namespaces, ignoring surrounding whitespace and empty entries
+func parseNamespaces(s string) ([]string, error) {
+	var namespaces []string
+	for _, namespace := range strings.Split(s, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" || slices.Contains(namespaces, namespace) {
+			continue
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	if len(namespaces) == 0 {
+		return nil, errors.Errorf("no namespaces given: %q", s)
+	}
+	return namespaces, nil
+}
+
+// build the cache options for the given namespace scope; only the explicitly listed namespaces restrict the cache,
+// whereas the label selector is evaluated per request (so objects of namespaces not matching the selector are still cached)
+func buildCacheOptions(namespaceScope reloader.NamespaceScope) cache.Options {
+	options := cache.Options{}
+	if len(namespaceScope.Namespaces) > 0 {
+		options.DefaultNamespaces = make(map[string]cache.Config)
+		for _, namespace := range namespaceScope.Namespaces {
+			options.DefaultNamespaces[namespace] = cache.Config{}
+		}
+	}
+	return options
+}
+
 func parseAddress(address string) (string, int, error) {
 	host, p, err := net.SplitHostPort(address)
 	if err != nil {
@@ -156,3 +454,55 @@ func checkInCluster() (bool, string, error) {
 
 	return true, string(namespace), nil
 }
+
+func buildWebhookRegistrationOptions(name string, serviceNamespace string, serviceName string, servicePort int, namespaceSelector string, objectSelector string, workloadResources string, failurePolicy string, timeout time.Duration, enablePods bool, enableImmutableConfig bool) (webhook.RegistrationOptions, error) {
+	options := webhook.RegistrationOptions{
+		Name:                  name,
+		ServiceNamespace:      serviceNamespace,
+		ServiceName:           serviceName,
+		EnablePods:            enablePods,
+		EnableImmutableConfig: enableImmutableConfig,
+	}
+	if servicePort < 1 || servicePort > 65535 {
+		return options, errors.Errorf("invalid webhook service port: %d", servicePort)
+	}
+	options.ServicePort = int32(servicePort)
+	if namespaceSelector != "" {
+		selector, err := metav1.ParseToLabelSelector(namespaceSelector)
+		if err != nil {
+			return options, errors.Wrap(err, "invalid webhook namespace selector")
+		}
+		options.NamespaceSelector = selector
+	}
+	if objectSelector != "" {
+		selector, err := metav1.ParseToLabelSelector(objectSelector)
+		if err != nil {
+			return options, errors.Wrap(err, "invalid webhook object selector")
+		}
+		options.ObjectSelector = selector
+	}
+	for _, resource := range strings.Split(workloadResources, ",") {
+		resource = strings.TrimSpace(resource)
+		if resource == "" {
+			continue
+		}
+		if !slices.Contains(webhook.SupportedWorkloadResources, resource) {
+			return options, errors.Errorf("invalid webhook workload resource: %s", resource)
+		}
+		options.WorkloadResources = append(options.WorkloadResources, resource)
+	}
+	if len(options.WorkloadResources) == 0 {
+		return options, errors.Errorf("no webhook workload resources given")
+	}
+	switch admissionv1.FailurePolicyType(failurePolicy) {
+	case admissionv1.Fail, admissionv1.Ignore:
+		options.FailurePolicy = admissionv1.FailurePolicyType(failurePolicy)
+	default:
+		return options, errors.Errorf("invalid webhook failure policy: %s", failurePolicy)
+	}
+	if timeout < time.Second || timeout > 30*time.Second {
+		return options, errors.Errorf("invalid webhook timeout: %s", timeout)
+	}
+	options.TimeoutSeconds = int32(timeout / time.Second)
+	return options, nil
+}
@@ -34,6 +34,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -159,9 +160,10 @@ var _ = BeforeSuite(func() {
 		})
 		Expect(err).NotTo(HaveOccurred())
 
-		err = controller.SetupControllerWithManager(mgr)
+		err = controller.SetupControllerWithManager(mgr, controller.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		err = webhook.SetupMutatingWebhookWithManager(mgr, webhook.Options{})
 		Expect(err).NotTo(HaveOccurred())
-		webhook.SetupMutatingWebhookWithManager(mgr)
 
 		By("starting manager")
 		threads.Add(1)
@@ -406,6 +408,109 @@ var _ = Describe("Validate reload", func() {
 	})
 })
 
+var _ = Describe("Validate resource references", func() {
+	It("should start with pre-existing referencing workloads, and restart them if the referenced resource changes", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+			},
+			Data: map[string]string{
+				"key": uuid.NewString(),
+			},
+		}
+		err := cli.Create(ctx, configMap)
+		Expect(err).NotTo(HaveOccurred())
+
+		app := uuid.NewString()
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+				Annotations: map[string]string{
+					reloader.AnnotationResources: reloader.FormatResourceReference(corev1.SchemeGroupVersion.WithKind("ConfigMap"), configMap.Name),
+				},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"app": app,
+					},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"app": app,
+						},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "dummy",
+								Image: "registry.k8s.io/pause:3.7",
+							},
+						},
+					},
+				},
+			},
+		}
+		err = cli.Create(ctx, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		waitForReloadComplete(deployment, 10*time.Second)
+
+		By("starting a manager with resource references enabled")
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme: scheme,
+			Controller: ctrlconfig.Controller{
+				SkipNameValidation: &[]bool{true}[0],
+				CacheSyncTimeout:   30 * time.Second,
+			},
+			Metrics: metricsserver.Options{
+				BindAddress: "0",
+			},
+			HealthProbeBindAddress: "0",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		err = controller.SetupControllerWithManager(mgr, controller.Options{EnableResourceReferences: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		mgrCtx, mgrCancel := context.WithCancel(ctx)
+		var mgrThreads sync.WaitGroup
+		defer func() {
+			mgrCancel()
+			mgrThreads.Wait()
+		}()
+		mgrThreads.Add(1)
+		go func() {
+			defer mgrThreads.Done()
+			defer GinkgoRecover()
+			err := mgr.Start(mgrCtx)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		By("changing the referenced resource")
+		// the watch for config maps is registered asynchronously, after the manager has started
+		Eventually(func() error {
+			if err := cli.Get(ctx, types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}, configMap); err != nil {
+				return err
+			}
+			configMap.Data["key"] = uuid.NewString()
+			if err := cli.Update(ctx, configMap); err != nil {
+				return err
+			}
+			oldHash := deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]
+			if err := cli.Get(ctx, types.NamespacedName{Namespace: deployment.Namespace, Name: deployment.Name}, deployment); err != nil {
+				return err
+			}
+			if deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash] == oldHash {
+				return fmt.Errorf("deployment not yet restarted - try again")
+			}
+			return nil
+		}, "30s", "5s").Should(Succeed())
+		waitForReloadComplete(deployment, 10*time.Second)
+	})
+})
+
 func createNamespace() string {
 	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "test-"}}
 	err := cli.Create(ctx, namespace)
@@ -0,0 +1,265 @@
+/*
+SPDX-FileCopyrightText: 2026 SAP SE or an SAP affiliate company and pod-reloader contributors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/uuid"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+
+	"github.com/sap/pod-reloader/internal/controller"
+	"github.com/sap/pod-reloader/internal/reloader"
+	"github.com/sap/pod-reloader/internal/webhook"
+)
+
+const (
+	// field manager used by pod-reloader to maintain the configuration hash
+	hashFieldManager = "pod-reloader"
+	// field manager used to simulate a GitOps tool (such as Argo CD) applying manifests from git
+	gitopsFieldManager = "argocd-controller"
+)
+
+func TestGitOps(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("Skipped because KUBEBUILDER_ASSETS is not set")
+	}
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GitOps")
+}
+
+var testEnv *envtest.Environment
+var cli ctrlclient.Client
+var ctx context.Context
+var cancel context.CancelFunc
+var threads sync.WaitGroup
+var namespace string
+
+var _ = BeforeSuite(func() {
+	log.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+	ctx, cancel = context.WithCancel(context.TODO())
+
+	By("bootstrapping test environment")
+	testEnv = &envtest.Environment{
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			MutatingWebhooks: []*admissionv1.MutatingWebhookConfiguration{
+				buildMutatingWebhookConfiguration(),
+			},
+		},
+	}
+	cfg, err := testEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+	webhookInstallOptions := &testEnv.WebhookInstallOptions
+
+	By("populating scheme")
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	By("initializing client")
+	cli, err = ctrlclient.New(cfg, ctrlclient.Options{Scheme: scheme})
+	Expect(err).NotTo(HaveOccurred())
+
+	By("creating manager")
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme: scheme,
+		WebhookServer: ctrlwebhook.NewServer(ctrlwebhook.Options{
+			Host:    webhookInstallOptions.LocalServingHost,
+			Port:    webhookInstallOptions.LocalServingPort,
+			CertDir: webhookInstallOptions.LocalServingCertDir,
+		}),
+		Metrics: metricsserver.Options{
+			BindAddress: "0",
+		},
+		HealthProbeBindAddress: "0",
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	err = controller.SetupControllerWithManager(mgr, controller.Options{HashFieldManager: hashFieldManager})
+	Expect(err).NotTo(HaveOccurred())
+	err = webhook.SetupMutatingWebhookWithManager(mgr, webhook.Options{HashFieldManager: hashFieldManager})
+	Expect(err).NotTo(HaveOccurred())
+
+	By("starting manager")
+	threads.Add(1)
+	go func() {
+		defer threads.Done()
+		defer GinkgoRecover()
+		err := mgr.Start(ctx)
+		Expect(err).NotTo(HaveOccurred())
+	}()
+
+	By("waiting for operator to become ready")
+	Eventually(func() error { return mgr.GetWebhookServer().StartedChecker()(nil) }, "10s", "100ms").Should(Succeed())
+
+	By("creating testing namespace")
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "test-"}}
+	Expect(cli.Create(ctx, ns)).To(Succeed())
+	namespace = ns.Name
+})
+
+var _ = AfterSuite(func() {
+	By("tearing down the test environment")
+	cancel()
+	threads.Wait()
+	if testEnv != nil {
+		err := testEnv.Stop()
+		Expect(err).NotTo(HaveOccurred())
+	}
+})
+
+var _ = Describe("Validate field manager mode", func() {
+	It("should maintain the configuration hash through server-side apply, such that it can be ignored through managed fields", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    namespace,
+				GenerateName: "test-",
+			},
+			Data: map[string]string{
+				"key": uuid.NewString(),
+			},
+		}
+		Expect(cli.Create(ctx, configMap)).To(Succeed())
+
+		By("applying deployment from git")
+		manifest := buildDeploymentManifest(namespace, "test", configMap.Name)
+		Expect(cli.Patch(ctx, manifest.DeepCopy(), ctrlclient.Apply, ctrlclient.FieldOwner(gitopsFieldManager))).To(Succeed())
+		deployment := &appsv1.Deployment{}
+		Expect(cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "test"}, deployment)).To(Succeed())
+		initialHash := deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]
+		Expect(initialHash).NotTo(BeEmpty())
+		// the initial hash is set by the webhook (so it is owned by the gitops manager); the controller then applies the same hash
+		Eventually(func(g Gomega) {
+			g.Expect(cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "test"}, deployment)).To(Succeed())
+			g.Expect(managedFields(deployment, hashFieldManager).Has(configHashPath())).To(BeTrue())
+		}, 10*time.Second, 100*time.Millisecond).Should(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).To(Equal(initialHash))
+
+		By("changing config map")
+		configMap.Data["key"] = uuid.NewString()
+		Expect(cli.Update(ctx, configMap)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "test"}, deployment)).To(Succeed())
+			g.Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).NotTo(Equal(initialHash))
+		}, 10*time.Second, 100*time.Millisecond).Should(Succeed())
+		hash := deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]
+		Expect(managedFields(deployment, hashFieldManager).Has(configHashPath())).To(BeTrue())
+		Expect(managedFields(deployment, gitopsFieldManager).Has(configHashPath())).To(BeFalse())
+
+		By("re-applying deployment from git")
+		Expect(cli.Patch(ctx, manifest.DeepCopy(), ctrlclient.Apply, ctrlclient.FieldOwner(gitopsFieldManager))).To(Succeed())
+		Expect(cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "test"}, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).To(Equal(hash))
+		// Argo CD's managedFieldsManagers setting ignores all fields owned by the listed managers; so the only difference between
+		// the live object and git (the hash) is ignored if pod-reloader's field manager is listed
+		Expect(managedFields(deployment, hashFieldManager).Has(configHashPath())).To(BeTrue())
+		Expect(managedFields(deployment, gitopsFieldManager).Has(configHashPath())).To(BeFalse())
+
+		By("updating deployment with a manifest lacking the hash")
+		deployment.Spec.Template.Annotations = nil
+		Expect(cli.Update(ctx, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[reloader.AnnotationConfigHash]).To(Equal(hash))
+	})
+})
+
+func buildMutatingWebhookConfiguration() *admissionv1.MutatingWebhookConfiguration {
+	return &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mutate",
+		},
+		Webhooks: []admissionv1.MutatingWebhook{{
+			Name:                    "mutate.test.local",
+			AdmissionReviewVersions: []string{"v1"},
+			ClientConfig: admissionv1.WebhookClientConfig{
+				Service: &admissionv1.ServiceReference{
+					Path: &[]string{"/mutate"}[0],
+				},
+			},
+			Rules: []admissionv1.RuleWithOperations{{
+				Operations: []admissionv1.OperationType{
+					admissionv1.Create,
+					admissionv1.Update,
+				},
+				Rule: admissionv1.Rule{
+					APIGroups:   []string{"apps"},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"deployments", "statefulsets", "daemonsets"},
+				},
+			}},
+			SideEffects: &[]admissionv1.SideEffectClass{admissionv1.SideEffectClassNone}[0],
+		}},
+	}
+}
+
+func buildDeploymentManifest(namespace string, name string, configMapName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"namespace": namespace,
+			"name":      name,
+			"annotations": map[string]any{
+				reloader.AnnotationConfigMaps: configMapName,
+			},
+		},
+		"spec": map[string]any{
+			"selector": map[string]any{
+				"matchLabels": map[string]any{"app": name},
+			},
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"labels": map[string]any{"app": name},
+				},
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "dummy", "image": "registry.k8s.io/pause:3.7"},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func managedFields(object metav1.Object, manager string) *fieldpath.Set {
+	set := &fieldpath.Set{}
+	for _, entry := range object.GetManagedFields() {
+		if entry.Manager != manager || entry.FieldsV1 == nil {
+			continue
+		}
+		s := &fieldpath.Set{}
+		Expect(s.FromJSON(bytes.NewReader(entry.FieldsV1.Raw))).To(Succeed())
+		set = set.Union(s)
+	}
+	return set
+}
+
+func configHashPath() fieldpath.Path {
+	return fieldpath.MakePathOrDie("spec", "template", "metadata", "annotations", reloader.AnnotationConfigHash)
+}